@@ -0,0 +1,28 @@
+package appendnote
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/journal"
+)
+
+// Execute runs the append command, adding text to today's daily note
+func Execute(folderPath string, verbose bool, text string, task bool) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	note, entry, err := journal.AppendEntry(folderPath, time.Now(), text, task)
+	if err != nil {
+		return err
+	}
+
+	if verbose {
+		fmt.Printf("Appended to %s\n", note.Path)
+	}
+	fmt.Printf("%s\n", entry)
+	return nil
+}
@@ -0,0 +1,67 @@
+package exportics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/ical"
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Options configures the export-ics command
+type Options struct {
+	Verbose bool
+	Output  string // File to write; empty writes to standard output
+	ical.NoteOptions
+	Tags markdown.TagFilter
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// Execute runs the export-ics command, writing each dated note as an all-day calendar event
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanDatedNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+	var selected []markdown.Note
+	for _, note := range notes {
+		if opts.Tags.Matches(note.Tags) {
+			selected = append(selected, note)
+		}
+	}
+	// Check for results
+	if len(selected) == 0 {
+		return fmt.Errorf("no notes found")
+	}
+
+	calendar := ical.Calendar{
+		Name:   "Journal",
+		Events: ical.FromNotes(folderPath, selected, opts.NoteOptions),
+		Stamp:  time.Now(),
+	}
+
+	var w io.Writer = os.Stdout
+	if opts.Output != "" {
+		file, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %v", opts.Output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := calendar.Write(w); err != nil {
+		return fmt.Errorf("error writing calendar: %v", err)
+	}
+	if opts.Output != "" {
+		fmt.Printf("Exported %d notes to %s\n", len(calendar.Events), opts.Output)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/travis-mark/salthaven/internal/graph"
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// DefaultFormat is the export format used when none is given
+const DefaultFormat = graph.FormatDOT
+
+// Options configures the graph command
+type Options struct {
+	Verbose bool
+	Format  string // dot, graphml or json
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// Execute runs the graph command, writing the vault's link graph to standard output
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	g := graph.Build(folderPath, notes)
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "%d notes, %d links\n", len(g.Nodes), len(g.Edges))
+	}
+	return g.Write(os.Stdout, opts.Format)
+}
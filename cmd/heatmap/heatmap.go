@@ -0,0 +1,91 @@
+package heatmap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// Options configures the heatmap command
+type Options struct {
+	Verbose bool
+	Metric  string // notes or words
+	Years   []int  // Years to show; empty shows every year with notes up to today
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// levelBlocks draws each intensity level, from nothing written to the busiest days
+var levelBlocks = []rune{'·', '░', '▒', '▓', '█'}
+
+// weekdayLabels label the rows, Monday first
+var weekdayLabels = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// FormatYear renders a year as rows of weekdays and columns of weeks
+func FormatYear(year stats.HeatmapYear, metric string) string {
+	weeks := year.Weeks()
+	grid := make([][]rune, 7)
+	for row := range grid {
+		grid[row] = []rune(strings.Repeat(" ", weeks))
+	}
+
+	// Label each month above the week it starts in, where there is room
+	months := []rune(strings.Repeat(" ", weeks+3))
+	for _, day := range year.Days {
+		grid[stats.Weekday(day.Date)][day.Week()] = levelBlocks[day.Level]
+		if day.Date.Day() == 1 {
+			col := day.Week()
+			if col == 0 || months[col-1] == ' ' {
+				copy(months[col:], []rune(day.Date.Format("Jan")))
+			}
+		}
+	}
+
+	total := year.Notes
+	if metric == stats.MetricWords {
+		total = year.Words
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d  %d %s\n", year.Year, total, metric)
+	fmt.Fprintf(&b, "     %s\n", strings.TrimRight(string(months), " "))
+	for row, cells := range grid {
+		fmt.Fprintf(&b, "%s  %s\n", weekdayLabels[row], strings.TrimRight(string(cells), " "))
+	}
+	return b.String()
+}
+
+// Execute runs the heatmap command, drawing journaling activity for each year
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+	metric, err := stats.ParseMetric(opts.Metric)
+	if err != nil {
+		return err
+	}
+
+	notes, err := markdown.ScanDatedNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+	// Check for results
+	if len(notes) == 0 {
+		return fmt.Errorf("no notes found")
+	}
+
+	for i, year := range stats.Heatmap(notes, metric, opts.Years, time.Now()) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(FormatYear(year, metric))
+	}
+	fmt.Printf("\nLess %s More\n", string(levelBlocks))
+
+	return nil
+}
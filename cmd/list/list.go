@@ -3,31 +3,125 @@ package list
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/travis-mark/salthaven/internal/events"
+	"github.com/travis-mark/salthaven/internal/gitlog"
+	"github.com/travis-mark/salthaven/internal/ical"
 	"github.com/travis-mark/salthaven/internal/markdown"
 )
 
+// Options controls which notes the list command shows
+type Options struct {
+	Verbose bool
+	Match   string // Match expression, see markdown.ParseMatcher
+	LeapDay markdown.LeapDayPolicy
+	Tags    markdown.TagFilter
+	Digest  markdown.Digest // Gather the whole week or month from previous years instead of matching
+	// Frontmatter properties holding dates, each matched independently
+	DateProperties []string
+	// Recurring events from a YAML/CSV file and a folder of person notes
+	EventsFile   string
+	PeopleFolder string
+	// Local git repositories whose commits from this day are listed, and whose
+	// commits count; empty means each repository's configured user.email
+	GitRepos  []string
+	GitAuthor string
+	// When no notes match, show the closest within this many days instead; zero disables
+	Nearest int
+}
+
 // Execute runs the onthisday command
-func Execute(folderPath string, verbose bool) error {
+func Execute(folderPath string, opts Options) error {
 	// Check if folder exists
 	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
 		return fmt.Errorf("folder does not exist: %s", folderPath)
 	}
-	// Scan for notes on this day using the same day matcher
+	matcher, err := markdown.ParseMatcher(opts.Match, opts.LeapDay)
+	if err != nil {
+		return err
+	}
+	if opts.Digest != markdown.DigestNone {
+		matcher = opts.Digest.Matcher()
+	}
+	// Scan for notes matching today's date
 	today := time.Now()
-	notes, err := markdown.ScanMarkdownNotes(folderPath, markdown.SameDayMatcher, today, verbose)
+	allNotes, err := markdown.ScanDatedNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+	notes := markdown.SelectNotes(allNotes, matcher, today, opts.Tags)
+	allEvents, err := events.Load(folderPath, opts.EventsFile, opts.PeopleFolder)
+	if err != nil {
+		return err
+	}
+	occurrences := events.Select(allEvents, matcher, today)
+	calendar, err := ical.ScanFolder(folderPath, opts.Verbose)
 	if err != nil {
 		return fmt.Errorf("error scanning folder: %v", err)
 	}
+	calendarEvents := ical.Select(calendar, matcher, today)
+	allCommits, err := gitlog.LoadAll(folderPath, opts.GitRepos, opts.GitAuthor)
+	if err != nil {
+		return err
+	}
+	commits := gitlog.Select(allCommits, matcher, today)
+	// Fall back to the notes dated closest to today
+	var nearest []markdown.NearestMatch
+	if len(notes) == 0 && opts.Nearest > 0 && opts.Digest == markdown.DigestNone {
+		nearest = markdown.SelectNearest(allNotes, opts.Nearest, today, opts.Tags)
+	}
 	// Check for results
-	if len(notes) == 0 {
+	if len(notes) == 0 && len(nearest) == 0 && len(occurrences) == 0 && len(calendarEvents) == 0 && len(commits) == 0 {
 		return fmt.Errorf("no notes found")
 	}
-	// Display results
+	// Display recurring and calendar events and commits above the notes
+	for _, occurrence := range occurrences {
+		fmt.Printf("%s\n", occurrence)
+	}
+	for _, occurrence := range calendarEvents {
+		fmt.Printf("%s\n", occurrence)
+	}
+	for _, commit := range commits {
+		fmt.Printf("%s\n", commit)
+	}
+	if len(occurrences)+len(calendarEvents)+len(commits) > 0 && (len(notes) > 0 || len(nearest) > 0) {
+		fmt.Println()
+	}
+	// Display results, grouped by year for digests
+	if opts.Digest != markdown.DigestNone {
+		fmt.Printf("%s\n", opts.Digest.Label(today))
+		for _, group := range markdown.GroupByYear(notes, opts.Digest.Year) {
+			fmt.Printf("\n%d\n", group.Year)
+			for _, note := range group.Notes {
+				fmt.Printf("  %s  %s%s\n", note.Day.Format("Mon Jan 2"), note.Path, dayOf(note))
+			}
+		}
+		return nil
+	}
 	for _, note := range notes {
-		fmt.Printf("%s\n", note)
+		fmt.Printf("%s%s\n", note.Path, dayOf(note))
+	}
+	for _, note := range nearest {
+		fmt.Printf("%s (%s)%s\n", note.Path, note.OffsetLabel(), dayOf(note.Match))
 	}
 
 	return nil
 }
+
+// dayOf describes which date property and which day of a multi-day note
+// matched, or is empty for a single-day match on the date property
+func dayOf(note markdown.Match) string {
+	var details []string
+	if note.Source.Property != markdown.PrimaryDateProperty {
+		details = append(details, note.Source.Property)
+	}
+	if note.DayCount() > 1 {
+		details = append(details, fmt.Sprintf("day %d of %d", note.DayNumber(), note.DayCount()))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
@@ -0,0 +1,36 @@
+package newnote
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/journal"
+)
+
+// Execute runs the new command, creating the daily note for date from the vault template
+func Execute(folderPath string, verbose bool, date time.Time, templatePath string) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	note, err := journal.Daily(folderPath, date, templatePath)
+	if err != nil {
+		return fmt.Errorf("error reading daily notes settings: %v", err)
+	}
+	if verbose {
+		if note.TemplatePath != "" {
+			fmt.Printf("Using template: %s\n", note.TemplatePath)
+		} else {
+			fmt.Printf("No template configured, using default\n")
+		}
+	}
+
+	if err := note.Create(folderPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", note.Path)
+	return nil
+}
@@ -0,0 +1,47 @@
+package random
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Options configures the random command
+type Options struct {
+	Verbose bool
+	markdown.RandomOptions
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// Execute runs the random command, showing a random dated note
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanDatedNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	note, ok := markdown.PickRandom(notes, opts.RandomOptions, time.Now())
+	// Check for results
+	if !ok {
+		return fmt.Errorf("no dated notes found")
+	}
+	// Display the note
+	relPath, err := filepath.Rel(folderPath, note.Path)
+	if err != nil {
+		relPath = note.Path
+	}
+	fmt.Printf("%s  %s\n\n", note.Date.Format("Monday, January 2, 2006"), relPath)
+	fmt.Printf("%s\n", strings.TrimSpace(markdown.Body(note.Content)))
+
+	return nil
+}
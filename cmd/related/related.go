@@ -0,0 +1,73 @@
+package related
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/search"
+)
+
+// DefaultLimit is the number of related notes shown when no limit is given
+const DefaultLimit = 5
+
+// Options configures the related command
+type Options struct {
+	Verbose bool
+	Note    string // Path of the note, relative to the vault or the working directory
+	Limit   int
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// vaultRelativePath returns the note's path within the vault. A path that
+// exists as given is taken relative to the working directory, anything else
+// relative to the vault.
+func vaultRelativePath(folderPath, note string) string {
+	if _, err := os.Stat(note); err == nil {
+		absNote, errNote := filepath.Abs(note)
+		absFolder, errFolder := filepath.Abs(folderPath)
+		if errNote == nil && errFolder == nil {
+			if rel, err := filepath.Rel(absFolder, absNote); err == nil {
+				return rel
+			}
+		}
+	}
+	return note
+}
+
+// Execute runs the related command, listing the notes most similar to a note
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	idx := search.Build(folderPath, notes)
+	docID, ok := idx.Lookup(vaultRelativePath(folderPath, opts.Note))
+	if !ok {
+		return fmt.Errorf("note not found in %s: %s", folderPath, opts.Note)
+	}
+
+	related := idx.Related(docID, opts.Limit)
+	// Check for results
+	if len(related) == 0 {
+		return fmt.Errorf("no related notes found")
+	}
+	// Display results
+	for _, note := range related {
+		date := "          "
+		if note.Dated() {
+			date = note.Date.Format("2006-01-02")
+		}
+		fmt.Printf("%s  %s  (%.0f%%)\n", date, note.RelPath, note.Score*100)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package review
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/review"
+)
+
+// Options configures the review command
+type Options struct {
+	Verbose bool
+	Year    int
+	Output  string // Vault-relative note to write the review to; empty prints it
+	Force   bool   // Overwrite the output note if it exists
+	review.Options
+	// Frontmatter properties holding dates
+	DateProperties []string
+}
+
+// Execute runs the review command, summarising a year of notes as Markdown
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	r := review.Build(folderPath, notes, opts.Year, opts.Options)
+	// Check for results
+	if r.Notes == 0 {
+		return fmt.Errorf("no notes found for %d", opts.Year)
+	}
+
+	// Display results
+	if opts.Output == "" {
+		fmt.Print(r.Markdown())
+		return nil
+	}
+
+	path := opts.Output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(folderPath, filepath.FromSlash(path))
+	}
+	if filepath.Ext(path) == "" {
+		path += ".md"
+	}
+	if _, err := os.Stat(path); err == nil && !opts.Force {
+		return fmt.Errorf("note already exists: %s (use --force to replace it)", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating folder: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(r.Markdown()), 0644); err != nil {
+		return fmt.Errorf("error writing review: %v", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
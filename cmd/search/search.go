@@ -0,0 +1,74 @@
+package search
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/search"
+)
+
+// DefaultLimit is the number of results shown when no limit is given
+const DefaultLimit = 20
+
+// Options configures the search command
+type Options struct {
+	Verbose bool
+	Query   string
+	Limit   int // Most results to show; zero shows all
+	// Frontmatter properties holding dates, for year: filters
+	DateProperties []string
+}
+
+// highlightTerminal emboldens matched words on a terminal, and marks them **like this** otherwise
+func highlightTerminal() func(string) string {
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return func(word string) string { return "\033[1m" + word + "\033[0m" }
+	}
+	return func(word string) string { return "**" + word + "**" }
+}
+
+// Execute runs the search command, listing the notes matching the query best first
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	query, err := search.ParseQuery(opts.Query)
+	if err != nil {
+		return err
+	}
+	if query.Empty() {
+		return fmt.Errorf("empty search query")
+	}
+
+	notes, err := markdown.ScanNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	results := search.Build(folderPath, notes).Search(query)
+	// Check for results
+	if len(results) == 0 {
+		return fmt.Errorf("no notes found matching %q", opts.Query)
+	}
+	// Display results
+	highlight := highlightTerminal()
+	for i, result := range results {
+		if opts.Limit > 0 && i >= opts.Limit {
+			fmt.Printf("… and %d more\n", len(results)-opts.Limit)
+			break
+		}
+		date := "          "
+		if result.Dated() {
+			date = result.Date.Format("2006-01-02")
+		}
+		fmt.Printf("%s  %s\n", date, result.RelPath)
+		if snippet := result.Snippet.Format(highlight); snippet != "" {
+			fmt.Printf("    %s\n", snippet)
+		}
+	}
+
+	return nil
+}
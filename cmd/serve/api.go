@@ -0,0 +1,223 @@
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/journal"
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// DefaultHost is the interface serve listens on unless told otherwise, so the
+// write endpoints are only reachable from this machine
+const DefaultHost = "127.0.0.1"
+
+// isLoopback reports whether host, a name or address without a port, is this machine
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkWriteRequest guards the endpoints that change the vault. Requests must
+// be JSON, which a page on another site cannot send without a CORS preflight
+// this server never answers, and any Origin must be this server. On a loopback
+// server the Host must be loopback too, so a DNS name rebound to 127.0.0.1
+// cannot reach it.
+func checkWriteRequest(r *http.Request, opts Options) (int, error) {
+	if r.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, fmt.Errorf("method not allowed")
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return http.StatusUnsupportedMediaType, fmt.Errorf("content type must be application/json")
+	}
+
+	host := opts.Host
+	if host == "" {
+		host = DefaultHost
+	}
+	requestHost, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		requestHost = r.Host
+	}
+	if isLoopback(host) && !isLoopback(requestHost) {
+		return http.StatusForbidden, fmt.Errorf("host %s is not allowed", r.Host)
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return http.StatusForbidden, fmt.Errorf("cross-origin request from %s is not allowed", origin)
+		}
+	}
+	return 0, nil
+}
+
+// appendRequest is the JSON body accepted by the append endpoint
+type appendRequest struct {
+	Text string `json:"text"`
+	Task bool   `json:"task"`
+}
+
+// appendResponse reports where an appended entry was written
+type appendResponse struct {
+	Path  string `json:"path"`
+	Entry string `json:"entry"`
+}
+
+// writeJSON sends v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError sends an error message as a JSON response
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// parseBool accepts the values HTML forms and shortcuts commonly send for a flag
+func parseBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "on", "yes":
+		return true
+	}
+	return false
+}
+
+// rejectWriteRequest answers a request checkWriteRequest refuses, returning
+// whether it did
+func rejectWriteRequest(w http.ResponseWriter, r *http.Request, opts Options) bool {
+	status, err := checkWriteRequest(r, opts)
+	if err == nil {
+		return false
+	}
+	if status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", http.MethodPost)
+	}
+	writeJSONError(w, status, err.Error())
+	return true
+}
+
+// handleAppend appends a timestamped bullet or task to today's daily note,
+// given a JSON body with text and task
+func handleAppend(folderPath string, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rejectWriteRequest(w, r, opts) {
+			return
+		}
+
+		var req appendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+			return
+		}
+
+		if strings.TrimSpace(req.Text) == "" {
+			writeJSONError(w, http.StatusBadRequest, "text is required")
+			return
+		}
+
+		note, entry, err := journal.AppendEntry(folderPath, time.Now(), req.Text, req.Task)
+		if err != nil {
+			if opts.Verbose {
+				fmt.Printf("Warning: Could not append to daily note: %v\n", err)
+			}
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+		writeJSON(w, http.StatusOK, appendResponse{Path: filepath.ToSlash(relPath), Entry: entry})
+	}
+}
+
+// resolveNotePath maps a vault-relative note path from a request to a file,
+// rejecting anything that is not a markdown file inside the vault
+func resolveNotePath(folderPath, relPath string) (string, error) {
+	if relPath == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path is outside the vault: %s", relPath)
+	}
+	if !strings.HasSuffix(strings.ToLower(clean), ".md") {
+		return "", fmt.Errorf("not a markdown note: %s", relPath)
+	}
+
+	path := filepath.Join(folderPath, clean)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("note not found: %s", relPath)
+	}
+	return path, nil
+}
+
+// toggleTaskRequest identifies a task line and the version of the file it was read from
+type toggleTaskRequest struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Hash string `json:"hash"`
+}
+
+// toggleTaskResponse reports the task's new state and the file's new version
+type toggleTaskResponse struct {
+	Checked bool   `json:"checked"`
+	Hash    string `json:"hash"`
+}
+
+// handleToggleTask flips a task checkbox in the source markdown file. The
+// request carries the hash the page was rendered from; if the file has been
+// edited since, it answers 409 Conflict instead of overwriting the edit.
+func handleToggleTask(folderPath string, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rejectWriteRequest(w, r, opts) {
+			return
+		}
+
+		var req toggleTaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+			return
+		}
+
+		path, err := resolveNotePath(folderPath, req.Path)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		hash, checked, err := markdown.ToggleTask(path, req.Line, req.Hash)
+		if err != nil {
+			if errors.Is(err, markdown.ErrContentChanged) {
+				writeJSONError(w, http.StatusConflict, "note changed on disk since the page was loaded")
+				return
+			}
+			if opts.Verbose {
+				fmt.Printf("Warning: Could not toggle task in %s: %v\n", path, err)
+			}
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, toggleTaskResponse{Checked: checked, Hash: hash})
+	}
+}
@@ -0,0 +1,169 @@
+package serve
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/travis-mark/salthaven/internal/gitlog"
+	"github.com/travis-mark/salthaven/internal/photos"
+)
+
+// vaultCache keeps the last scan of the notes, with the search index and link
+// graph built from it, until a note, calendar or events file changes
+type vaultCache struct {
+	folderPath string
+	opts       Options
+
+	mu        sync.Mutex
+	loaded    bool
+	signature uint64
+	notes     vault // Everything read from the notes, events and calendar files
+
+	photosLoaded    bool
+	photosSignature uint64
+	photos          []photos.Photo
+
+	commitsLoaded bool
+	refs          string // The repositories' refs when the commits were loaded
+	commits       []gitlog.Commit
+}
+
+// newVaultCache returns an empty cache; the vault is scanned on first use
+func newVaultCache(folderPath string, opts Options) *vaultCache {
+	return &vaultCache{folderPath: folderPath, opts: opts}
+}
+
+// hashFiles adds the path, size and modification time of the files under
+// root that keep returns true for, so any edit, addition or removal changes the hash
+func hashFiles(h hash.Hash64, root string, keep func(path string) bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !keep(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed since the folder was listed
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+}
+
+// vaultSignature hashes the files a scan of the notes reads. Walking the
+// folder is much cheaper than reading and indexing every note.
+func vaultSignature(folderPath string, opts Options) (uint64, error) {
+	h := fnv.New64a()
+	notesAndCalendars := func(path string) bool {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".md" || ext == ".ics"
+	}
+	if err := hashFiles(h, folderPath, notesAndCalendars); err != nil {
+		return 0, err
+	}
+
+	// The events file and people folder may be outside the vault
+	if opts.EventsFile != "" {
+		if info, err := os.Stat(resolveVaultPath(folderPath, opts.EventsFile)); err == nil {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\n", opts.EventsFile, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	if opts.PeopleFolder != "" {
+		hashFiles(h, resolveVaultPath(folderPath, opts.PeopleFolder), notesAndCalendars)
+	}
+	return h.Sum64(), nil
+}
+
+// resolveVaultPath resolves a path relative to the vault
+func resolveVaultPath(folderPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(folderPath, filepath.FromSlash(path))
+}
+
+// scan returns the notes, events and calendar files with the search index and
+// link graph, scanning them again only when they changed since the last scan.
+// The vault returned is shared, so callers must not modify it.
+func (c *vaultCache) scan() (vault, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	signature, err := vaultSignature(c.folderPath, c.opts)
+	if err != nil {
+		return vault{}, err
+	}
+	if !c.loaded || signature != c.signature {
+		notes, err := scanVault(c.folderPath, c.opts)
+		if err != nil {
+			return vault{}, err
+		}
+		c.notes, c.signature, c.loaded = notes, signature, true
+	}
+	return c.notes, nil
+}
+
+// load returns the whole vault: the cached scan of the notes, with the photos and commits.
+// Pages that only need the notes, search index or link graph use scan.
+func (c *vaultCache) load() (vault, error) {
+	v, err := c.scan()
+	if err != nil {
+		return vault{}, err
+	}
+	v.photos = c.scanPhotos()
+	v.commits = c.loadCommits()
+	return v, nil
+}
+
+// scanPhotos returns the photos, reading their EXIF data again only when a
+// photo was added, changed or removed since the last scan
+func (c *vaultCache) scanPhotos() []photos.Photo {
+	folder := photosFolder(c.folderPath, c.opts)
+	if folder == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := fnv.New64a()
+	if err := hashFiles(h, folder, photos.IsPhoto); err != nil {
+		// Let the scan report the error
+		return loadPhotos(c.folderPath, c.opts)
+	}
+	if !c.photosLoaded || h.Sum64() != c.photosSignature {
+		c.photos = loadPhotos(c.folderPath, c.opts)
+		c.photosSignature, c.photosLoaded = h.Sum64(), true
+	}
+	return c.photos
+}
+
+// loadCommits returns the repositories' commits, reading the history again
+// only when a ref moved since it was last read
+func (c *vaultCache) loadCommits() []gitlog.Commit {
+	if len(c.opts.GitRepos) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	refs, err := gitlog.Refs(c.folderPath, c.opts.GitRepos)
+	if err != nil {
+		// Let the load report the error
+		return loadCommits(c.folderPath, c.opts)
+	}
+	if !c.commitsLoaded || refs != c.refs {
+		c.commits = loadCommits(c.folderPath, c.opts)
+		c.refs, c.commitsLoaded = refs, true
+	}
+	return c.commits
+}
@@ -0,0 +1,170 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// CalendarDay is one day of the year, counting the notes written on it in any year
+type CalendarDay struct {
+	Day   int
+	Key   string // MM-DD, which the page script compares with today
+	Date  string // YYYY-MM-DD in the reference year, for the day page link
+	Notes int
+}
+
+// CalendarMonth is the days of one month
+type CalendarMonth struct {
+	Name string
+	Days []CalendarDay
+}
+
+// CalendarPageData represents the data passed to the calendar template
+type CalendarPageData struct {
+	Months []CalendarMonth
+	Notes  int
+	Static bool // Exported as a static site, without the server's dynamic pages
+}
+
+const calendarTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>On This Day - Calendar</title>
+    <style>
+{{template "style"}}
+        .note h2 {
+            color: var(--text-accent);
+            font-size: 1.1em;
+            margin: 0 0 10px 0;
+        }
+        .calendar-days {
+            display: grid;
+            grid-template-columns: repeat(7, 1fr);
+            gap: 4px;
+        }
+        .calendar-day {
+            display: block;
+            padding: 6px 0;
+            border-radius: 6px;
+            text-align: center;
+            text-decoration: none;
+            color: var(--text-tertiary);
+            border: 1px solid var(--border-color);
+        }
+        .calendar-day.has-notes {
+            color: var(--text-accent);
+            font-weight: 600;
+        }
+        .calendar-day.today {
+            background: var(--text-accent);
+            color: var(--bg-secondary);
+        }
+        .calendar-day:hover {
+            border-color: var(--text-accent);
+        }
+        .calendar-count {
+            display: block;
+            font-size: 0.7em;
+            font-weight: normal;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>On This Day</h1>
+        <p>{{.Notes}} dated notes • <a href="/" class="content-link" id="today-link">Today</a></p>
+    </div>
+
+    {{range .Months}}
+    <div class="note">
+        <h2>{{.Name}}</h2>
+        <div class="calendar-days">
+            {{range .Days}}
+            <a href="/?date={{.Date}}" class="calendar-day{{if .Notes}} has-notes{{end}}" data-day="{{.Key}}" title="{{.Notes}} {{if eq .Notes 1}}note{{else}}notes{{end}}">{{.Day}}{{if .Notes}}<span class="calendar-count">{{.Notes}}</span>{{end}}</a>
+            {{end}}
+        </div>
+    </div>
+    {{end}}
+
+    <div class="footer">
+        {{if .Static}}
+        Generated by Salthaven
+        {{else}}
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/stats">Stats</a> • <a href="/heatmap">Heatmap</a> • <a href="javascript:location.reload()">Refresh</a>
+        {{end}}
+    </div>
+
+    <script>
+{{template "theme-script"}}
+
+        // Mark today, which for an exported site is only known when the page is opened
+        document.addEventListener('DOMContentLoaded', function() {
+            const now = new Date();
+            const key = String(now.getMonth() + 1).padStart(2, '0') + '-' + String(now.getDate()).padStart(2, '0');
+            const today = document.querySelector('.calendar-day[data-day="' + key + '"]');
+            if (today) {
+                today.classList.add('today');
+                document.getElementById('today-link').href = today.getAttribute('href');
+            }
+        });
+    </script>
+</body>
+</html>`
+
+// calendarYear returns the year a month and day are shown in: this year, or
+// the latest leap year for February 29
+func calendarYear(month time.Month, day int, now time.Time) int {
+	year := now.Year()
+	if month == time.February && day == 29 {
+		for !markdown.IsLeapYear(year) {
+			year--
+		}
+	}
+	return year
+}
+
+// calendarPage counts the notes each day of the year shows, from any year,
+// matching them as the day pages do
+func calendarPage(notes []markdown.Note, matcher markdown.DateMatcher, now time.Time) CalendarPageData {
+	data := CalendarPageData{Notes: len(notes)}
+	for month := time.January; month <= time.December; month++ {
+		calendarMonth := CalendarMonth{Name: month.String()}
+		// A leap year has every day, including February 29
+		days := time.Date(2024, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		for day := 1; day <= days; day++ {
+			date := time.Date(calendarYear(month, day, now), month, day, 0, 0, 0, 0, now.Location())
+			calendarMonth.Days = append(calendarMonth.Days, CalendarDay{
+				Day:   day,
+				Key:   fmt.Sprintf("%02d-%02d", month, day),
+				Date:  date.Format("2006-01-02"),
+				Notes: len(markdown.SelectNotes(notes, matcher, date, markdown.TagFilter{})),
+			})
+		}
+		data.Months = append(data.Months, calendarMonth)
+	}
+	return data
+}
+
+// handleCalendar renders every day of the year, linking to each day's notes
+func handleCalendar(opts Options, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		matcher, err := markdown.ParseMatcher(opts.Match, opts.LeapDay)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		renderPage(w, "calendar", calendarPage(v.dated, matcher, time.Now()))
+	}
+}
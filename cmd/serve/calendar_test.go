@@ -0,0 +1,82 @@
+package serve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// datedNote returns a note dated by its frontmatter, as a vault scan reads it
+func datedNote(t *testing.T, date string) markdown.Note {
+	t.Helper()
+	content := "---\ndate: " + date + "\n---\n"
+	dates, err := markdown.ParseYAMLDates(content, markdown.DefaultDateProperties)
+	if err != nil {
+		t.Fatalf("ParseYAMLDates(%q) error = %v", date, err)
+	}
+	return markdown.Note{Path: date + ".md", Content: content, Date: dates[0].Start, End: dates[0].End, Dates: dates}
+}
+
+func TestCalendarYear(t *testing.T) {
+	tests := []struct {
+		month time.Month
+		day   int
+		now   int
+		want  int
+	}{
+		{time.February, 29, 2023, 2020},
+		{time.February, 29, 2024, 2024},
+		{time.February, 29, 2100, 2096},
+		{time.February, 28, 2100, 2100},
+		{time.March, 1, 2023, 2023},
+	}
+	for _, tt := range tests {
+		now := time.Date(tt.now, time.June, 1, 0, 0, 0, 0, time.UTC)
+		if got := calendarYear(tt.month, tt.day, now); got != tt.want {
+			t.Errorf("calendarYear(%s %d, %d) = %d, want %d", tt.month, tt.day, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarPageLeapDay(t *testing.T) {
+	notes := []markdown.Note{datedNote(t, "2020-02-29")}
+
+	// The counts on February 28, February 29 and March 1 for each policy. The
+	// February 29 cell always shows the latest leap year, so it always counts
+	// the note; the others show this year.
+	tests := []struct {
+		policy markdown.LeapDayPolicy
+		year   int
+		want   [3]int
+	}{
+		{markdown.LeapDayExact, 2023, [3]int{0, 1, 0}},
+		{markdown.LeapDayFeb28, 2023, [3]int{1, 1, 0}},
+		{markdown.LeapDayMar1, 2023, [3]int{0, 1, 1}},
+		{markdown.LeapDayBoth, 2023, [3]int{1, 1, 1}},
+		{markdown.LeapDayExact, 2024, [3]int{0, 1, 0}},
+		{markdown.LeapDayFeb28, 2024, [3]int{0, 1, 0}},
+		{markdown.LeapDayMar1, 2024, [3]int{0, 1, 0}},
+		{markdown.LeapDayBoth, 2024, [3]int{0, 1, 0}},
+		{markdown.LeapDayExact, 2100, [3]int{0, 1, 0}},
+		{markdown.LeapDayFeb28, 2100, [3]int{1, 1, 0}},
+		{markdown.LeapDayMar1, 2100, [3]int{0, 1, 1}},
+		{markdown.LeapDayBoth, 2100, [3]int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		matcher, err := markdown.ParseMatcher("", tt.policy)
+		if err != nil {
+			t.Fatalf("ParseMatcher(%s) error = %v", tt.policy, err)
+		}
+		data := calendarPage(notes, matcher, time.Date(tt.year, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+		february, march := data.Months[time.February-1].Days, data.Months[time.March-1].Days
+		if len(february) != 29 {
+			t.Fatalf("%s %d: February has %d days, want 29", tt.policy, tt.year, len(february))
+		}
+		got := [3]int{february[27].Notes, february[28].Notes, march[0].Notes}
+		if got != tt.want {
+			t.Errorf("%s %d: Feb 28, Feb 29, Mar 1 counts = %v, want %v", tt.policy, tt.year, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package serve
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// Chart dimensions, in SVG user units
+const (
+	chartHeight    = 160
+	chartBarWidth  = 28
+	chartBarGap    = 8
+	chartLabelRoom = 20 // Below the bars, for the labels
+	chartValueRoom = 16 // Above the tallest bar, for its count
+)
+
+// shortLabel abbreviates month and weekday names to three letters
+func shortLabel(label string) string {
+	if len(label) > 4 && strings.IndexFunc(label, func(r rune) bool { return r >= '0' && r <= '9' }) < 0 {
+		return label[:3]
+	}
+	return label
+}
+
+// barChart renders note counts as an inline SVG bar chart, styled by the
+// .chart classes so it follows the light and dark themes
+func barChart(counts []stats.Count) template.HTML {
+	largest := stats.Max(counts)
+	width := len(counts)*(chartBarWidth+chartBarGap) + chartBarGap
+	plotHeight := chartHeight - chartLabelRoom - chartValueRoom
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" width="100%%" role="img">`, width, chartHeight)
+	for i, count := range counts {
+		x := chartBarGap + i*(chartBarWidth+chartBarGap)
+		barHeight := 0
+		if largest > 0 {
+			barHeight = count.Notes * plotHeight / largest
+		}
+		if count.Notes > 0 && barHeight == 0 {
+			barHeight = 1
+		}
+		y := chartValueRoom + plotHeight - barHeight
+		label := html.EscapeString(count.Label)
+
+		fmt.Fprintf(&b, `<g><title>%s: %d notes, %d words</title>`, label, count.Notes, count.Words)
+		fmt.Fprintf(&b, `<rect class="chart-bar" x="%d" y="%d" width="%d" height="%d" rx="3"></rect>`, x, y, chartBarWidth, barHeight)
+		if count.Notes > 0 {
+			fmt.Fprintf(&b, `<text class="chart-value" x="%d" y="%d" text-anchor="middle">%d</text>`, x+chartBarWidth/2, y-4, count.Notes)
+		}
+		fmt.Fprintf(&b, `<text class="chart-label" x="%d" y="%d" text-anchor="middle">%s</text></g>`,
+			x+chartBarWidth/2, chartHeight-6, html.EscapeString(shortLabel(count.Label)))
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// Heatmap cell dimensions, in SVG user units
+const (
+	heatmapCell   = 11
+	heatmapGap    = 2
+	heatmapLeft   = 28 // Room for the weekday labels
+	heatmapTop    = 16 // Room for the month labels
+	heatmapStride = heatmapCell + heatmapGap
+)
+
+// heatmapSVG renders a year of daily activity as a GitHub-style grid of weeks.
+// Days with notes link to that date's notes.
+func heatmapSVG(year stats.HeatmapYear) template.HTML {
+	width := heatmapLeft + year.Weeks()*heatmapStride
+	height := heatmapTop + 7*heatmapStride
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="heatmap" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="Notes written in %d">`, width, height, year.Year)
+	for row, label := range []string{"Mon", "", "Wed", "", "Fri", "", ""} {
+		if label != "" {
+			fmt.Fprintf(&b, `<text class="chart-label" x="0" y="%d">%s</text>`, heatmapTop+row*heatmapStride+heatmapCell-1, label)
+		}
+	}
+
+	for _, day := range year.Days {
+		x := heatmapLeft + day.Week()*heatmapStride
+		y := heatmapTop + stats.Weekday(day.Date)*heatmapStride
+		if day.Date.Day() == 1 {
+			fmt.Fprintf(&b, `<text class="chart-label" x="%d" y="%d">%s</text>`, x, heatmapTop-5, day.Date.Format("Jan"))
+		}
+
+		date := day.Date.Format("2006-01-02")
+		cell := fmt.Sprintf(`<rect class="heat-%d" x="%d" y="%d" width="%d" height="%d" rx="2"><title>%s: %d notes, %d words</title></rect>`,
+			day.Level, x, y, heatmapCell, heatmapCell, day.Date.Format("Mon Jan 2, 2006"), day.Notes, day.Words)
+		if day.Notes > 0 {
+			fmt.Fprintf(&b, `<a href="/?date=%s&amp;match=exact">%s</a>`, date, cell)
+		} else {
+			b.WriteString(cell)
+		}
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
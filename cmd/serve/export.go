@@ -0,0 +1,250 @@
+package serve
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Where the exported pages go, relative to the output folder
+const (
+	exportIndex    = "index.html" // The calendar
+	exportDaysDir  = "days"       // One page per day of the year, named MM-DD.html
+	exportNotesDir = "notes"      // One page per dated note, mirroring the vault's folders
+)
+
+// serverLinkRegex matches the server-absolute links the page templates use
+var serverLinkRegex = regexp.MustCompile(` href="/([^"]*)"`)
+
+// dayPagePath returns the exported page of a month and day
+func dayPagePath(month time.Month, day int) string {
+	return fmt.Sprintf("%s/%02d-%02d.html", exportDaysDir, month, day)
+}
+
+// notePagePath returns the exported page of a vault-relative note path
+func notePagePath(relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	return exportNotesDir + "/" + strings.TrimSuffix(relPath, path.Ext(relPath)) + ".html"
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a link
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// staticLink maps a server link to the exported page it shows, relative to
+// root. Links to notes that are not exported return false, and links to other
+// pages that are not exported are returned unchanged.
+func staticLink(link, root string, exported map[string]bool) (string, bool) {
+	link = strings.ReplaceAll(link, "&amp;", "&")
+	route, rawQuery, _ := strings.Cut(link, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "/" + link, true
+	}
+
+	switch route {
+	case "", "calendar":
+		if date, err := time.Parse("2006-01-02", query.Get("date")); err == nil {
+			return root + dayPagePath(date.Month(), date.Day()), true
+		}
+		return root + exportIndex, true
+	case "note":
+		notePath := filepath.ToSlash(query.Get("path"))
+		if !exported[notePath] {
+			return "", false
+		}
+		return root + escapePath(notePagePath(notePath)), true
+	}
+	return "/" + link, true
+}
+
+// staticLinks rewrites the server links of a rendered page to relative links
+// between the exported files, so the site also works opened from disk. Links
+// to notes that are not exported, such as undated backlinks, are dropped,
+// leaving their text.
+func staticLinks(page []byte, pagePath string, exported map[string]bool) []byte {
+	root := strings.Repeat("../", strings.Count(pagePath, "/"))
+	return serverLinkRegex.ReplaceAllFunc(page, func(match []byte) []byte {
+		link, ok := staticLink(string(serverLinkRegex.FindSubmatch(match)[1]), root, exported)
+		if !ok {
+			return nil
+		}
+		return []byte(` href="` + link + `"`)
+	})
+}
+
+// writePage renders a page template to a file in the output folder, exported
+// holding the vault-relative paths of the notes with a page
+func writePage(outDir, pagePath, name string, data interface{}, exported map[string]bool) error {
+	tmpl, err := parsePage(name)
+	if err != nil {
+		return err
+	}
+	var page bytes.Buffer
+	if err := tmpl.Execute(&page, data); err != nil {
+		return fmt.Errorf("error rendering %s: %v", pagePath, err)
+	}
+
+	file := filepath.Join(outDir, filepath.FromSlash(pagePath))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, staticLinks(page.Bytes(), pagePath, exported), 0644)
+}
+
+// copyFile copies an attachment into the output folder
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyAttachments copies the files a note links to, such as images, next to
+// its exported page so relative links from the note still resolve
+func copyAttachments(folderPath, outDir string, note markdown.Note) (int, error) {
+	copied := 0
+	for _, link := range markdown.ExtractLinks(note.Content) {
+		target := filepath.FromSlash(link.Target)
+		if ext := filepath.Ext(target); ext == "" || strings.EqualFold(ext, ".md") {
+			continue
+		}
+
+		// Obsidian resolves attachments next to the note, then from the vault root
+		var src string
+		for _, candidate := range []string{filepath.Join(filepath.Dir(note.Path), target), filepath.Join(folderPath, target)} {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				src = candidate
+				break
+			}
+		}
+		relPath, err := filepath.Rel(folderPath, src)
+		if src == "" || err != nil || strings.HasPrefix(relPath, "..") {
+			continue
+		}
+
+		if err := copyFile(src, filepath.Join(outDir, exportNotesDir, relPath)); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// hideLocalPaths drops the notes' paths on this machine, which only the
+// Obsidian links need and a published site should not give away
+func hideLocalPaths(notes []NoteEntry) {
+	for i := range notes {
+		notes[i].FullPath = ""
+	}
+}
+
+// ExportSite renders the calendar, a page for every day of the year and a page
+// for every dated note to outDir as static HTML, using the serve templates
+func ExportSite(folderPath, outDir string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+	matcher, err := markdown.ParseMatcher(opts.Match, opts.LeapDay)
+	if err != nil {
+		return err
+	}
+	// The gallery's photos come from the server's /photo route, which a
+	// static site does not have
+	opts.PhotosFolder = ""
+
+	v, err := loadVault(folderPath, opts)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+	// Check for results
+	if len(v.dated) == 0 {
+		return fmt.Errorf("no notes found")
+	}
+
+	// Only dated notes get a page, so links to any other note are dropped
+	exported := make(map[string]bool, len(v.dated))
+	for _, note := range v.dated {
+		if relPath, err := filepath.Rel(folderPath, note.Path); err == nil {
+			exported[filepath.ToSlash(relPath)] = true
+		}
+	}
+
+	now := time.Now()
+	calendar := calendarPage(v.dated, matcher, now)
+	calendar.Static = true
+	if err := writePage(outDir, exportIndex, "calendar", calendar, exported); err != nil {
+		return err
+	}
+
+	days := 0
+	for _, month := range calendar.Months {
+		for _, day := range month.Days {
+			date, _ := time.ParseInLocation("2006-01-02", day.Date, time.Local)
+			data := dayPage(folderPath, v, opts, dayQuery{
+				Matcher:       matcher,
+				MatchExpr:     opts.Match,
+				Date:          date,
+				FormattedDate: date.Format("January 2"),
+			})
+			data.Static = true
+			hideLocalPaths(data.Notes)
+			if data.Random != nil {
+				data.Random.FullPath = ""
+			}
+			if err := writePage(outDir, dayPagePath(date.Month(), date.Day()), "onthisday", data, exported); err != nil {
+				return err
+			}
+			days++
+		}
+	}
+
+	attachments := 0
+	for _, note := range v.dated {
+		entry := newNoteEntry(folderPath, note)
+		data := notePage(v, entry)
+		data.Static = true
+		hideLocalPaths(data.Notes)
+		if err := writePage(outDir, notePagePath(entry.Path), "onthisday", data, exported); err != nil {
+			return err
+		}
+
+		copied, err := copyAttachments(folderPath, outDir, note)
+		if err != nil {
+			return fmt.Errorf("error copying attachments of %s: %v", entry.Path, err)
+		}
+		attachments += copied
+	}
+
+	fmt.Printf("Exported %d day pages, %d note pages and %d attachments to %s\n", days, len(v.dated), attachments, outDir)
+	return nil
+}
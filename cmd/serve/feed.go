@@ -0,0 +1,266 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// feedDays is how many days back the feeds go, today included
+const feedDays = 30
+
+// FeedDay is one feed entry: the notes written on a day in earlier years
+type FeedDay struct {
+	Date  time.Time // Midnight at the start of the day
+	Notes []NoteEntry
+}
+
+// ID returns the entry's GUID in the feed with the given ID, which stays the
+// same however often the feed is read
+func (d FeedDay) ID(feedID string) string {
+	return feedID + ":" + d.Date.Format("2006-01-02")
+}
+
+// Title describes the entry, such as "On This Day: October 18 (3 memories)"
+func (d FeedDay) Title() string {
+	memories := "1 memory"
+	if len(d.Notes) != 1 {
+		memories = fmt.Sprintf("%d memories", len(d.Notes))
+	}
+	return fmt.Sprintf("On This Day: %s (%s)", d.Date.Format("January 2"), memories)
+}
+
+// Content renders the day's notes as HTML, newest first, each linking to its note page
+func (d FeedDay) Content(baseURL string) string {
+	var b strings.Builder
+	for _, note := range d.Notes {
+		title := note.Title
+		if title == "" {
+			title = note.Path
+		}
+		link := baseURL + "/note?path=" + url.QueryEscape(note.Path)
+		fmt.Fprintf(&b, "<h2><a href=\"%s\">%s</a></h2>\n", html.EscapeString(link), html.EscapeString(title))
+		fmt.Fprintf(&b, "<p><em>%s</em></p>\n", note.Date.Format("Monday, January 2, 2006"))
+		b.WriteString(markdown.ToHTML(note.Content))
+	}
+	return b.String()
+}
+
+// feedDaysEnding gathers, for each of the last feedDays days, the notes from
+// earlier years matching it. Days without any are left out.
+func feedDaysEnding(folderPath string, notes []markdown.Note, matcher markdown.DateMatcher, tags markdown.TagFilter, today time.Time) []FeedDay {
+	var days []FeedDay
+	start := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	for i := 0; i < feedDays; i++ {
+		day := FeedDay{Date: start.AddDate(0, 0, -i)}
+		for _, match := range markdown.SelectNotes(notes, matcher, day.Date, tags) {
+			if match.Source.Start.Year() < day.Date.Year() {
+				day.Notes = append(day.Notes, newMatchEntry(folderPath, match))
+			}
+		}
+		if len(day.Notes) == 0 {
+			continue
+		}
+		sort.Slice(day.Notes, func(i, j int) bool {
+			return day.Notes[i].Date.After(day.Notes[j].Date)
+		})
+		days = append(days, day)
+	}
+	return days
+}
+
+// Atom feed elements (RFC 4287)
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// RSS feed elements (RSS 2.0)
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// baseURL returns the scheme and host the request was made to, for absolute feed links
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// feedID returns the feed's ID. Feeds filtered by tag get their own, so
+// readers subscribed to several don't take their entries for the same ones.
+func feedID(tags markdown.TagFilter) string {
+	id := "urn:salthaven:onthisday"
+	values := url.Values{}
+	for _, tag := range tags.Include {
+		values.Add(tagParam, tag)
+	}
+	for _, tag := range tags.Exclude {
+		values.Add(excludeTagParam, tag)
+	}
+	for _, key := range []string{tagParam, excludeTagParam} {
+		sort.Strings(values[key])
+	}
+	if len(values) > 0 {
+		id += ":" + values.Encode()
+	}
+	return id
+}
+
+// dayURL links to the page of a day, keeping the feed's tag filter
+func dayURL(base string, date time.Time, tags markdown.TagFilter) string {
+	values := url.Values{"date": {date.Format("2006-01-02")}}
+	for _, tag := range tags.Include {
+		values.Add(tagParam, tag)
+	}
+	for _, tag := range tags.Exclude {
+		values.Add(excludeTagParam, tag)
+	}
+	return base + "/?" + values.Encode()
+}
+
+// writeXML writes a feed document with its content type. The feed is encoded
+// before anything is sent, so an error can still be answered with a 500.
+func writeXML(w http.ResponseWriter, contentType string, feed interface{}) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		http.Error(w, fmt.Sprintf("Feed error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(b.Bytes())
+}
+
+// handleFeed serves the last days' memories as an Atom or RSS feed, format
+// being "atom" or "rss". The tag and exclude-tag query parameters filter the notes.
+func handleFeed(folderPath string, opts Options, cache *vaultCache, format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		matcher, err := markdown.ParseMatcher(opts.Match, opts.LeapDay)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		tags := tagFilterFromQuery(r.URL.Query())
+		now := time.Now()
+		days := feedDaysEnding(folderPath, v.dated, matcher, tags, now)
+		base := baseURL(r)
+		id := feedID(tags)
+
+		if format == "rss" {
+			feed := rssFeed{
+				Version: "2.0",
+				Channel: rssChannel{
+					Title:       "On This Day",
+					Link:        base + "/",
+					Description: "Notes written on this day in earlier years",
+				},
+			}
+			for _, day := range days {
+				feed.Channel.Items = append(feed.Channel.Items, rssItem{
+					Title:       day.Title(),
+					Link:        dayURL(base, day.Date, tags),
+					GUID:        rssGUID{Value: day.ID(id)},
+					PubDate:     day.Date.Format(time.RFC1123Z),
+					Description: day.Content(base),
+				})
+			}
+			writeXML(w, "application/rss+xml; charset=utf-8", feed)
+			return
+		}
+
+		// Atom requires a feed date even when no day has memories
+		updated := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if len(days) > 0 {
+			updated = days[0].Date
+		}
+		feed := atomFeed{
+			Title:   "On This Day",
+			ID:      id,
+			Updated: updated.Format(time.RFC3339),
+			Author:  atomAuthor{Name: "Salthaven"},
+			Links: []atomLink{
+				{Href: base + r.URL.RequestURI(), Rel: "self", Type: "application/atom+xml"},
+				{Href: base + "/", Rel: "alternate", Type: "text/html"},
+			},
+		}
+		for _, day := range days {
+			feed.Entries = append(feed.Entries, atomEntry{
+				Title:   day.Title(),
+				ID:      day.ID(id),
+				Updated: day.Date.Format(time.RFC3339),
+				Link:    atomLink{Href: dayURL(base, day.Date, tags), Rel: "alternate", Type: "text/html"},
+				Content: atomContent{Type: "html", Body: day.Content(base)},
+			})
+		}
+		writeXML(w, "application/atom+xml; charset=utf-8", feed)
+	}
+}
@@ -0,0 +1,158 @@
+package serve
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// HeatmapYearView is one year of the heatmap page
+type HeatmapYearView struct {
+	Year  int
+	Total int // Notes or words, by the metric
+	Chart template.HTML
+}
+
+// HeatmapPageData represents the data passed to the heatmap template
+type HeatmapPageData struct {
+	Metric  string
+	Metrics []string
+	Years   []HeatmapYearView
+}
+
+const heatmapTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Journal Heatmap</title>
+    <style>
+{{template "style"}}
+        .filters {
+            text-align: center;
+            margin-bottom: 20px;
+        }
+        .filter {
+            display: inline-block;
+            margin: 0 4px;
+            padding: 4px 12px;
+            border-radius: 12px;
+            color: var(--text-secondary);
+            text-decoration: none;
+            border: 1px solid var(--border-color);
+        }
+        .filter.active {
+            color: var(--bg-secondary);
+            background: var(--text-accent);
+            border-color: var(--text-accent);
+        }
+        .note h2 {
+            color: var(--text-accent);
+            font-size: 1.1em;
+            margin: 0 0 10px 0;
+        }
+        .heatmap-total {
+            color: var(--text-tertiary);
+            font-weight: normal;
+            font-size: 0.8em;
+        }
+        .chart-label {
+            fill: var(--text-secondary);
+            font-size: 9px;
+            font-family: inherit;
+        }
+        .heat-0 { fill: var(--border-color); }
+        .heat-1 { fill: #9be9a8; }
+        .heat-2 { fill: #40c463; }
+        .heat-3 { fill: #30a14e; }
+        .heat-4 { fill: #216e39; }
+        .heatmap a rect:hover {
+            stroke: var(--text-accent);
+            stroke-width: 1;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>Journal Heatmap</h1>
+        <p>Click a day to read its notes • <a href="/" class="content-link">On This Day</a></p>
+    </div>
+
+    <div class="filters">
+        {{$current := .Metric}}
+        {{range .Metrics}}
+        <a href="/heatmap?metric={{.}}" class="filter{{if eq . $current}} active{{end}}">{{.}}</a>
+        {{end}}
+    </div>
+
+    {{range .Years}}
+    <div class="note">
+        <h2>{{.Year}} <span class="heatmap-total">{{.Total}} {{$current}}</span></h2>
+        {{.Chart}}
+    </div>
+    {{else}}
+        <div class="no-notes">
+            No dated notes found
+        </div>
+    {{end}}
+
+    <div class="footer">
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/stats">Stats</a> • <a href="javascript:location.reload()">Refresh</a>
+    </div>
+
+    <script>
+{{template "theme-script"}}
+    </script>
+</body>
+</html>`
+
+// handleHeatmap renders a heatmap of each year's notes, newest year first.
+// The metric query parameter picks notes or words, and year limits the years.
+func handleHeatmap(cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		metric, err := stats.ParseMetric(query.Get("metric"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var years []int
+		for _, value := range queryList(query, "year") {
+			year, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid year %q, expected YYYY", value), http.StatusBadRequest)
+				return
+			}
+			years = append(years, year)
+		}
+
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		data := HeatmapPageData{
+			Metric:  metric,
+			Metrics: []string{stats.MetricNotes, stats.MetricWords},
+		}
+		if len(v.dated) > 0 {
+			heatmap := stats.Heatmap(v.dated, metric, years, time.Now())
+			for i := len(heatmap) - 1; i >= 0; i-- {
+				year := heatmap[i]
+				total := year.Notes
+				if metric == stats.MetricWords {
+					total = year.Words
+				}
+				data.Years = append(data.Years, HeatmapYearView{Year: year.Year, Total: total, Chart: heatmapSVG(year)})
+			}
+		}
+
+		renderPage(w, "heatmap", data)
+	}
+}
@@ -0,0 +1,46 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/ical"
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// handleICS serves the dated notes as a calendar to subscribe to. With
+// anniversaries=1 each note repeats yearly; tag and exclude-tag filter the notes.
+func handleICS(folderPath string, opts Options, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		tags := tagFilterFromQuery(query)
+		var selected []markdown.Note
+		for _, note := range v.dated {
+			if tags.Matches(note.Tags) {
+				selected = append(selected, note)
+			}
+		}
+
+		noteOpts := ical.NoteOptions{
+			Anniversaries: parseBool(query.Get("anniversaries")),
+			LeapDay:       opts.LeapDay,
+		}
+		calendar := ical.Calendar{
+			Name:   "Journal",
+			Events: ical.FromNotes(folderPath, selected, noteOpts),
+			Stamp:  time.Now(),
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		if err := calendar.Write(w); err != nil {
+			http.Error(w, fmt.Sprintf("Calendar error: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package serve
+
+// layoutTemplate defines the pieces shared by every page: the stylesheet,
+// the theme toggle button, the search box and the theme management script
+const layoutTemplate = `{{define "style"}}
+        :root {
+            --bg-primary: #f9f9f9;
+            --bg-secondary: white;
+            --text-primary: #333;
+            --text-secondary: #7f8c8d;
+            --text-tertiary: #95a5a6;
+            --text-accent: #2c3e50;
+            --text-content: #34495e;
+            --border-color: #eee;
+            --shadow: rgba(0,0,0,0.1);
+        }
+
+        @media (prefers-color-scheme: dark) {
+            :root {
+                --bg-primary: #1a1a1a;
+                --bg-secondary: #2d2d2d;
+                --text-primary: #e0e0e0;
+                --text-secondary: #b0b0b0;
+                --text-tertiary: #888;
+                --text-accent: #64b5f6;
+                --text-content: #d0d0d0;
+                --border-color: #444;
+                --shadow: rgba(0,0,0,0.3);
+            }
+        }
+
+        [data-theme="dark"] {
+            --bg-primary: #1a1a1a;
+            --bg-secondary: #2d2d2d;
+            --text-primary: #e0e0e0;
+            --text-secondary: #b0b0b0;
+            --text-tertiary: #888;
+            --text-accent: #64b5f6;
+            --text-content: #d0d0d0;
+            --border-color: #444;
+            --shadow: rgba(0,0,0,0.3);
+        }
+
+        [data-theme="light"] {
+            --bg-primary: #f9f9f9;
+            --bg-secondary: white;
+            --text-primary: #333;
+            --text-secondary: #7f8c8d;
+            --text-tertiary: #95a5a6;
+            --text-accent: #2c3e50;
+            --text-content: #34495e;
+            --border-color: #eee;
+            --shadow: rgba(0,0,0,0.1);
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, "Helvetica Neue", Arial, sans-serif;
+            line-height: 1.6;
+            max-width: 800px;
+            margin: 0 auto;
+            padding: 20px;
+            color: var(--text-primary);
+            background-color: var(--bg-primary);
+            transition: background-color 0.3s ease, color 0.3s ease;
+        }
+        .header {
+            text-align: center;
+            margin-bottom: 30px;
+            padding: 20px;
+            background: var(--bg-secondary);
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            position: relative;
+            transition: background-color 0.3s ease, box-shadow 0.3s ease;
+        }
+        .header h1 {
+            color: var(--text-accent);
+            margin: 0;
+            transition: color 0.3s ease;
+        }
+        .header p {
+            color: var(--text-secondary);
+            margin: 10px 0 0 0;
+            transition: color 0.3s ease;
+        }
+        .theme-toggle {
+            position: absolute;
+            top: 20px;
+            right: 20px;
+            background: none;
+            border: 2px solid var(--text-tertiary);
+            border-radius: 50%;
+            width: 40px;
+            height: 40px;
+            cursor: pointer;
+            font-size: 18px;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            transition: all 0.3s ease;
+            color: var(--text-tertiary);
+        }
+        .theme-toggle:hover {
+            border-color: var(--text-accent);
+            color: var(--text-accent);
+            transform: scale(1.1);
+        }
+        .note {
+            background: var(--bg-secondary);
+            margin: 20px 0;
+            padding: 20px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            transition: background-color 0.3s ease, box-shadow 0.3s ease;
+        }
+        .note-header {
+            border-bottom: 1px solid var(--border-color);
+            padding-bottom: 10px;
+            margin-bottom: 15px;
+            transition: border-color 0.3s ease;
+        }
+        .note-title {
+            font-size: 1.3em;
+            font-weight: bold;
+            color: var(--text-accent);
+            margin: 0;
+            transition: color 0.3s ease;
+        }
+        .note-title-link {
+            color: var(--text-accent);
+            text-decoration: none;
+            transition: all 0.3s ease;
+        }
+        .note-title-link:hover {
+            text-decoration: underline;
+            opacity: 0.8;
+        }
+        .note-date {
+            color: var(--text-secondary);
+            font-size: 0.9em;
+            margin: 5px 0;
+            transition: color 0.3s ease;
+        }
+        .note-day {
+            margin-left: 8px;
+            padding: 1px 8px;
+            border-radius: 10px;
+            background: var(--border-color);
+            font-size: 0.9em;
+        }
+        .note-path {
+            color: var(--text-tertiary);
+            font-size: 0.8em;
+            font-family: monospace;
+            text-decoration: none;
+            transition: color 0.3s ease;
+        }
+        a.note-path:hover {
+            text-decoration: underline;
+        }
+        .note-content {
+            white-space: pre-wrap;
+            color: var(--text-content);
+            transition: color 0.3s ease;
+        }
+        .events {
+            margin: 20px 0;
+        }
+        .event {
+            background: var(--bg-secondary);
+            margin: 10px 0;
+            padding: 12px 20px;
+            border-left: 4px solid var(--text-accent);
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            transition: background-color 0.3s ease, box-shadow 0.3s ease;
+        }
+        .event-icon {
+            margin-right: 6px;
+        }
+        .event-title {
+            font-weight: bold;
+            color: var(--text-accent);
+        }
+        .event-years {
+            color: var(--text-secondary);
+        }
+        .event-date {
+            color: var(--text-tertiary);
+            font-size: 0.8em;
+            margin-left: 28px;
+        }
+        .year-heading {
+            color: var(--text-secondary);
+            margin: 30px 0 10px 0;
+            padding-bottom: 5px;
+            border-bottom: 2px solid var(--border-color);
+            transition: color 0.3s ease, border-color 0.3s ease;
+        }
+        .photo-grid {
+            display: grid;
+            grid-template-columns: repeat(auto-fill, minmax(140px, 1fr));
+            gap: 8px;
+        }
+        .photo-grid img {
+            width: 100%;
+            aspect-ratio: 1;
+            object-fit: cover;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            display: block;
+        }
+        .no-notes {
+            text-align: center;
+            color: var(--text-secondary);
+            font-style: italic;
+            padding: 40px;
+            background: var(--bg-secondary);
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            transition: all 0.3s ease;
+        }
+        .random-more {
+            text-align: center;
+            margin: 10px 0;
+        }
+        .footer {
+            text-align: center;
+            margin-top: 30px;
+            padding: 20px;
+            color: var(--text-tertiary);
+            font-size: 0.9em;
+            transition: color 0.3s ease;
+        }
+        .footer a {
+            color: var(--text-accent);
+            text-decoration: none;
+            transition: color 0.3s ease;
+        }
+        .footer a:hover {
+            text-decoration: underline;
+        }
+        
+        /* Checkbox styling */
+        .checkbox-item {
+            display: flex;
+            align-items: flex-start;
+            margin: 2px 0;
+            line-height: 1.5;
+        }
+        .checkbox {
+            width: 12px;
+            height: 12px;
+            margin-right: 6px;
+            margin-top: 3px;
+            border: 1px solid var(--text-tertiary);
+            border-radius: 2px;
+            background: var(--bg-secondary);
+            flex-shrink: 0;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            cursor: pointer;
+            transition: background-color 0.2s ease;
+        }
+        .checkbox:focus-visible {
+            outline: 2px solid var(--text-accent);
+            outline-offset: 1px;
+        }
+        .checkbox.pending {
+            opacity: 0.5;
+        }
+        .checkbox.checked {
+            background: var(--text-accent);
+            border-color: var(--text-accent);
+        }
+        .checkbox.checked::after {
+            content: '✓';
+            color: var(--bg-secondary);
+            font-size: 9px;
+            font-weight: bold;
+            line-height: 1;
+        }
+        .checkbox-text {
+            flex: 1;
+            color: var(--text-content);
+        }
+        
+        /* Link styling */
+        .content-link {
+            color: var(--text-accent);
+            text-decoration: none;
+            transition: all 0.2s ease;
+        }
+        .content-link:hover {
+            text-decoration: underline;
+            opacity: 0.8;
+        }
+        .obsidian-link {
+            color: var(--text-accent);
+            text-decoration: none;
+            transition: all 0.2s ease;
+        }
+        .obsidian-link:hover {
+            text-decoration: underline;
+            opacity: 0.8;
+        }
+
+        /* Tag styling */
+        .tag-chips {
+            text-align: center;
+            margin-bottom: 20px;
+        }
+        .tag-chip {
+            display: inline-block;
+            margin: 3px;
+            border: 1px solid var(--border-color);
+            border-radius: 12px;
+            background: var(--bg-secondary);
+            font-size: 0.85em;
+            transition: all 0.2s ease;
+        }
+        .tag-chip a {
+            display: inline-block;
+            padding: 2px 8px;
+            color: var(--text-secondary);
+            text-decoration: none;
+        }
+        .tag-chip a.tag-exclude {
+            padding-left: 4px;
+            border-left: 1px solid var(--border-color);
+        }
+        .tag-chip.included {
+            background: var(--text-accent);
+            border-color: var(--text-accent);
+        }
+        .tag-chip.included a {
+            color: var(--bg-secondary);
+        }
+        .tag-chip.excluded a {
+            text-decoration: line-through;
+        }
+        .tag-chip.excluded a.tag-exclude {
+            text-decoration: none;
+        }
+        .tag-count {
+            color: var(--text-tertiary);
+            font-size: 0.85em;
+        }
+        .note-tags {
+            margin-top: 5px;
+        }
+        .note-tag {
+            color: var(--text-tertiary);
+            font-size: 0.8em;
+        }
+        .note-related {
+            margin-top: 15px;
+            padding-top: 10px;
+            border-top: 1px solid var(--border-color);
+            color: var(--text-tertiary);
+            font-size: 0.85em;
+        }
+        .note-related a {
+            color: var(--text-accent);
+            text-decoration: none;
+        }
+        .note-related a:hover {
+            text-decoration: underline;
+        }
+
+        /* Search */
+        .search-box {
+            display: flex;
+            gap: 8px;
+            max-width: 420px;
+            margin: 15px auto 0 auto;
+        }
+        .search-box input {
+            flex: 1;
+            padding: 6px 12px;
+            border: 1px solid var(--border-color);
+            border-radius: 16px;
+            background: var(--bg-primary);
+            color: var(--text-primary);
+            font-size: 0.95em;
+        }
+        .search-box button {
+            padding: 6px 14px;
+            border: 1px solid var(--text-accent);
+            border-radius: 16px;
+            background: none;
+            color: var(--text-accent);
+            cursor: pointer;
+        }
+        mark {
+            background: rgba(241, 196, 15, 0.4);
+            color: inherit;
+            border-radius: 2px;
+        }
+{{end}}
+
+{{define "search-box"}}
+        <form class="search-box" action="/search" method="get" role="search">
+            <input type="search" name="q" value="{{.}}" placeholder="Search notes, e.g. &quot;ice cream&quot; tag:travel year:2019" aria-label="Search notes">
+            <button type="submit">Search</button>
+        </form>
+{{end}}
+
+{{define "theme-toggle"}}
+        <button class="theme-toggle" onclick="toggleTheme()" title="Toggle dark/light mode">
+            <span class="theme-icon">🌙</span>
+        </button>
+{{end}}
+
+{{define "theme-script"}}
+        // Theme management
+        function getStoredTheme() {
+            return localStorage.getItem('theme');
+        }
+
+        function setStoredTheme(theme) {
+            localStorage.setItem('theme', theme);
+        }
+
+        function getPreferredTheme() {
+            const storedTheme = getStoredTheme();
+            if (storedTheme) {
+                return storedTheme;
+            }
+            return window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
+        }
+
+        function setTheme(theme) {
+            document.documentElement.setAttribute('data-theme', theme);
+            const themeIcon = document.querySelector('.theme-icon');
+            if (themeIcon) {
+                themeIcon.textContent = theme === 'dark' ? '☀️' : '🌙';
+            }
+        }
+
+        function toggleTheme() {
+            const currentTheme = document.documentElement.getAttribute('data-theme');
+            const newTheme = currentTheme === 'dark' ? 'light' : 'dark';
+            setTheme(newTheme);
+            setStoredTheme(newTheme);
+        }
+
+        // Initialize theme on page load
+        document.addEventListener('DOMContentLoaded', function() {
+            const preferredTheme = getPreferredTheme();
+            setTheme(preferredTheme);
+        });
+
+        // Listen for system theme changes
+        window.matchMedia('(prefers-color-scheme: dark)').addEventListener('change', function(e) {
+            if (!getStoredTheme()) {
+                setTheme(e.matches ? 'dark' : 'light');
+            }
+        });
+{{end}}`
@@ -0,0 +1,166 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/photos"
+)
+
+// thumbnailSize is the longest side of the gallery's thumbnails, in pixels
+const thumbnailSize = 320
+
+// PhotoEntry is a photo in the gallery
+type PhotoEntry struct {
+	Name     string
+	Date     time.Time
+	Timed    bool   // Date has the time the photo was taken, as read from EXIF
+	URL      string // Full photo
+	ThumbURL string
+}
+
+// When describes when the photo was taken, with the time when it is known
+func (p PhotoEntry) When() string {
+	if p.Timed {
+		return p.Date.Format("January 2, 2006 3:04 PM")
+	}
+	return p.Date.Format("January 2, 2006")
+}
+
+// PhotoYear is the gallery of photos taken in one year
+type PhotoYear struct {
+	Year   int
+	Photos []PhotoEntry
+}
+
+// photosFolder returns the configured photos folder, relative paths being
+// resolved against the vault, or "" when none is configured
+func photosFolder(folderPath string, opts Options) string {
+	if opts.PhotosFolder == "" || filepath.IsAbs(opts.PhotosFolder) {
+		return opts.PhotosFolder
+	}
+	return filepath.Join(folderPath, filepath.FromSlash(opts.PhotosFolder))
+}
+
+// photoGallery groups the photos by year, linking each through /photo
+func photoGallery(photosPath string, selected []photos.Photo) []PhotoYear {
+	var gallery []PhotoYear
+	for _, group := range photos.GroupByYear(selected) {
+		year := PhotoYear{Year: group.Year}
+		for _, photo := range group.Photos {
+			relPath, err := filepath.Rel(photosPath, photo.Path)
+			if err != nil {
+				continue
+			}
+			query := "/photo?path=" + url.QueryEscape(filepath.ToSlash(relPath))
+			year.Photos = append(year.Photos, PhotoEntry{
+				Name:     filepath.Base(photo.Path),
+				Date:     photo.Date,
+				Timed:    photo.DateSource == photos.SourceEXIF,
+				URL:      query,
+				ThumbURL: query + "&thumb=1",
+			})
+		}
+		gallery = append(gallery, year)
+	}
+	return gallery
+}
+
+// resolvePhotoPath checks a photos-folder-relative path and returns the file's
+// path, refusing anything outside the folder or that is not a photo
+func resolvePhotoPath(photosPath, relPath string) (string, error) {
+	if photosPath == "" {
+		return "", fmt.Errorf("no photos folder configured")
+	}
+	if relPath == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path is outside the photos folder: %s", relPath)
+	}
+	if !photos.IsPhoto(clean) {
+		return "", fmt.Errorf("not a photo: %s", relPath)
+	}
+
+	path := filepath.Join(photosPath, clean)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("photo not found: %s", relPath)
+	}
+	return path, nil
+}
+
+// thumbnailCache keeps the thumbnails made, one per photo, until the photo changes
+type thumbnailCache struct {
+	mu     sync.Mutex
+	thumbs map[string]cachedThumbnail
+}
+
+// cachedThumbnail is a thumbnail with the photo's size and modification time when it was made
+type cachedThumbnail struct {
+	size    int64
+	modTime time.Time
+	data    []byte
+}
+
+// get returns the photo's thumbnail, making it when the photo is new or changed
+func (c *thumbnailCache) get(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.thumbs[path]
+	c.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.data, nil
+	}
+
+	// Made without holding the lock, so slow photos don't hold up the others
+	thumb, err := photos.Thumbnail(path, thumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.thumbs[path] = cachedThumbnail{size: info.Size(), modTime: info.ModTime(), data: thumb}
+	c.mu.Unlock()
+	return thumb, nil
+}
+
+// handlePhoto serves a photo from the photos folder, or its thumbnail with thumb=1.
+// Photos without a thumbnail, such as most HEIC files, are served whole.
+func handlePhoto(folderPath string, opts Options) http.HandlerFunc {
+	thumbs := &thumbnailCache{thumbs: make(map[string]cachedThumbnail)}
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		path, err := resolvePhotoPath(photosFolder(folderPath, opts), query.Get("path"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		// Photos rarely change, so let the browser keep them
+		w.Header().Set("Cache-Control", "max-age=86400")
+		if query.Get("thumb") != "" {
+			thumb, err := thumbs.get(path)
+			if err == nil {
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Write(thumb)
+				return
+			}
+			if opts.Verbose {
+				fmt.Printf("Warning: Could not make a thumbnail of %s: %v\n", path, err)
+			}
+		}
+		http.ServeFile(w, r, path)
+	}
+}
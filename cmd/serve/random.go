@@ -0,0 +1,57 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// randomOptionsFromQuery reads the tag, exclude-tag, year and older query parameters
+func randomOptionsFromQuery(query url.Values) (markdown.RandomOptions, error) {
+	opts := markdown.RandomOptions{
+		Tags:        tagFilterFromQuery(query),
+		WeightOlder: parseBool(query.Get("older")),
+	}
+	for _, value := range queryList(query, "year") {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid year %q, expected YYYY", value)
+		}
+		opts.Years = append(opts.Years, year)
+	}
+	return opts, nil
+}
+
+// handleRandom redirects to a random dated note
+func handleRandom(folderPath string, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		randomOpts, err := randomOptionsFromQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		note, ok := markdown.PickRandom(v.dated, randomOpts, time.Now())
+		if !ok {
+			http.Error(w, "No dated notes found", http.StatusNotFound)
+			return
+		}
+
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+		http.Redirect(w, r, "/note?path="+url.QueryEscape(filepath.ToSlash(relPath)), http.StatusFound)
+	}
+}
@@ -0,0 +1,49 @@
+package serve
+
+import (
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/graph"
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/search"
+)
+
+// relatedLimit is the number of related notes shown under each note
+const relatedLimit = 3
+
+// RelatedNote links to a note similar to, or linking to, the one shown
+type RelatedNote struct {
+	Path  string
+	Title string
+	Date  time.Time // Zero when the note is undated
+}
+
+// addRelated lists the notes most similar to each entry, from any date
+func addRelated(idx *search.Index, entries []NoteEntry) {
+	for i := range entries {
+		docID, ok := idx.Lookup(entries[i].Path)
+		if !ok {
+			continue
+		}
+		for _, similar := range idx.Related(docID, relatedLimit) {
+			entries[i].Related = append(entries[i].Related, RelatedNote{
+				Path:  similar.RelPath,
+				Title: markdown.Title(similar.RelPath, similar.Content),
+				Date:  similar.Date,
+			})
+		}
+	}
+}
+
+// addBacklinks lists the notes linking to each entry
+func addBacklinks(links *graph.Graph, entries []NoteEntry) {
+	for i := range entries {
+		for _, node := range links.Backlinks(entries[i].Path) {
+			entries[i].Backlinks = append(entries[i].Backlinks, RelatedNote{
+				Path:  node.Path,
+				Title: node.Label,
+				Date:  node.Date,
+			})
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package serve
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/review"
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// ReviewPageData represents the data passed to the review template
+type ReviewPageData struct {
+	review.Review
+	MonthChart template.HTML
+}
+
+const reviewTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Year}} in Review</title>
+    <style>
+{{template "style"}}
+        .note h2 {
+            color: var(--text-accent);
+            font-size: 1.1em;
+            margin: 0 0 10px 0;
+        }
+        .chart-bar {
+            fill: var(--text-accent);
+        }
+        .chart-value, .chart-label {
+            fill: var(--text-secondary);
+            font-size: 10px;
+            font-family: inherit;
+        }
+        .review-list {
+            margin: 0;
+            padding-left: 20px;
+            color: var(--text-content);
+        }
+        .review-list a, .review-table a {
+            color: var(--text-accent);
+            text-decoration: none;
+        }
+        .review-list a:hover, .review-table a:hover {
+            text-decoration: underline;
+        }
+        .review-count {
+            color: var(--text-tertiary);
+            font-size: 0.85em;
+        }
+        .review-table {
+            width: 100%;
+            border-collapse: collapse;
+            color: var(--text-content);
+            font-size: 0.9em;
+        }
+        .review-table th, .review-table td {
+            padding: 4px 6px;
+            border-bottom: 1px solid var(--border-color);
+            text-align: left;
+        }
+        .review-table .number {
+            text-align: right;
+        }
+        .highlight {
+            padding: 10px 0;
+            border-bottom: 1px solid var(--border-color);
+        }
+        .highlight:last-child {
+            border-bottom: none;
+        }
+        .highlight-excerpt {
+            color: var(--text-content);
+            margin: 4px 0 0 0;
+            line-height: 1.5;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>{{.Year}} in Review</h1>
+        <p><a href="/review/{{.PreviousYear}}" class="content-link">← {{.PreviousYear}}</a> • {{.Notes}} entries on {{.Days}} days, {{.Words}} words • <a href="/review/{{.NextYear}}" class="content-link">{{.NextYear}} →</a></p>
+    </div>
+
+    {{if .Notes}}
+    <div class="note">
+        <h2>Entries per month</h2>
+        {{.MonthChart}}
+        <table class="review-table">
+            <tr><th>Month</th><th class="number">Entries</th><th class="number">Words</th><th>First</th><th>Last</th></tr>
+            {{range .Months}}
+            <tr>
+                <td>{{.Name}}</td>
+                <td class="number">{{.Notes}}</td>
+                <td class="number">{{.Words}}</td>
+                <td>{{with .First}}<a href="/note?path={{.Path}}" title="{{.Title}}">{{.Date.Format "Jan 2"}}</a>{{end}}</td>
+                <td>{{with .Last}}<a href="/note?path={{.Path}}" title="{{.Title}}">{{.Date.Format "Jan 2"}}</a>{{end}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+
+    {{if .Tags}}
+    <div class="note">
+        <h2>Top tags</h2>
+        <ol class="review-list">
+            {{range .Tags}}<li><a href="/search?q=tag:{{.Tag}}">#{{.Tag}}</a> <span class="review-count">{{.Count}}</span></li>{{end}}
+        </ol>
+    </div>
+    {{end}}
+
+    {{range .LinkedSections}}
+    <div class="note">
+        <h2>{{.Title}}</h2>
+        <ol class="review-list">
+            {{range .Linked}}<li>{{if .Path}}<a href="/note?path={{.Path}}">{{.Label}}</a>{{else}}{{.Label}}{{end}} <span class="review-count">{{.Count}}</span></li>{{end}}
+        </ol>
+    </div>
+    {{end}}
+
+    <div class="note">
+        <h2>Longest entries</h2>
+        <ol class="review-list">
+            {{range .Longest}}<li><a href="/note?path={{.Path}}">{{.Title}}</a> <span class="review-count">{{.Date.Format "Jan 2"}}, {{.Words}} words</span></li>{{end}}
+        </ol>
+    </div>
+
+    {{if .Highlights}}
+    <div class="note">
+        <h2>Highlights</h2>
+        {{range .Highlights}}
+        <div class="highlight">
+            <a href="/note?path={{.Path}}" class="note-path">{{.Date.Format "Monday, January 2"}} — {{.Title}}</a>
+            {{if .Excerpt}}<p class="highlight-excerpt">{{.Excerpt}}</p>{{end}}
+        </div>
+        {{end}}
+    </div>
+    {{end}}
+    {{else}}
+        <div class="no-notes">
+            Nothing was written in {{.Year}}
+        </div>
+    {{end}}
+
+    <div class="footer">
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/stats">Stats</a> • <a href="/heatmap">Heatmap</a> • <a href="javascript:location.reload()">Refresh</a>
+    </div>
+
+    <script>
+{{template "theme-script"}}
+    </script>
+</body>
+</html>`
+
+// LinkedSection is a titled list of notes linked from the year's entries
+type LinkedSection struct {
+	Title  string
+	Linked []review.Linked
+}
+
+// LinkedSections returns the people, places and other linked notes that have entries
+func (d ReviewPageData) LinkedSections() []LinkedSection {
+	var sections []LinkedSection
+	for _, section := range []LinkedSection{
+		{Title: "People", Linked: d.People},
+		{Title: "Places", Linked: d.Places},
+		{Title: "Most linked", Linked: d.Linked},
+	} {
+		if len(section.Linked) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// PreviousYear returns the year before the review's
+func (d ReviewPageData) PreviousYear() int {
+	return d.Year - 1
+}
+
+// NextYear returns the year after the review's
+func (d ReviewPageData) NextYear() int {
+	return d.Year + 1
+}
+
+// handleReview renders the year in review for the {year} path segment.
+// Without a year it redirects to last year's review.
+func handleReview(folderPath string, opts Options, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := r.PathValue("year")
+		if value == "" {
+			http.Redirect(w, r, fmt.Sprintf("/review/%d", time.Now().Year()-1), http.StatusFound)
+			return
+		}
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid year %q, expected YYYY", value), http.StatusBadRequest)
+			return
+		}
+
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		rev := review.Build(folderPath, v.notes, year, review.Options{
+			PeopleFolder: opts.PeopleFolder,
+			PlacesFolder: opts.PlacesFolder,
+		})
+		months := make([]stats.Count, len(rev.Months))
+		for i, month := range rev.Months {
+			months[i] = stats.Count{Label: month.Name, Notes: month.Notes, Words: month.Words}
+		}
+		renderPage(w, "review", ReviewPageData{Review: rev, MonthChart: barChart(months)})
+	}
+}
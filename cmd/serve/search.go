@@ -0,0 +1,123 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/travis-mark/salthaven/internal/search"
+)
+
+// searchLimit is the most results the search page shows
+const searchLimit = 50
+
+// SearchPageData represents the data passed to the search template
+type SearchPageData struct {
+	Query   string
+	Results []search.Result
+	Count   int // Total matches, which may exceed len(Results)
+	Error   string
+}
+
+const searchTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Search{{if .Query}} - {{.Query}}{{end}}</title>
+    <style>
+{{template "style"}}
+        .result {
+            padding: 12px 0;
+            border-bottom: 1px solid var(--border-color);
+        }
+        .result:last-child {
+            border-bottom: none;
+        }
+        .result-path {
+            color: var(--text-accent);
+            text-decoration: none;
+            font-weight: 600;
+        }
+        .result-path:hover {
+            text-decoration: underline;
+        }
+        .result-date {
+            color: var(--text-tertiary);
+            font-size: 0.85em;
+            margin-left: 8px;
+        }
+        .result-snippet {
+            color: var(--text-content);
+            margin-top: 4px;
+            line-height: 1.5;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>Search</h1>
+        {{if .Query}}<p>{{.Count}} {{if eq .Count 1}}note{{else}}notes{{end}} found • <a href="/" class="content-link">On This Day</a></p>{{end}}
+{{template "search-box" .Query}}
+    </div>
+
+    {{if .Error}}
+        <div class="no-notes">{{.Error}}</div>
+    {{else if .Results}}
+    <div class="note">
+        {{range .Results}}
+        <div class="result">
+            <a href="/note?path={{.RelPath}}" class="result-path">{{.RelPath}}</a>
+            {{if .Dated}}<span class="result-date">{{.Date.Format "January 2, 2006"}}</span>{{end}}
+            <div class="result-snippet">{{range .Snippet}}{{if .Match}}<mark>{{.Text}}</mark>{{else}}{{.Text}}{{end}}{{end}}</div>
+        </div>
+        {{end}}
+    </div>
+    {{else if .Query}}
+        <div class="no-notes">
+            No notes found matching “{{.Query}}”
+        </div>
+    {{end}}
+
+    <div class="footer">
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/tasks">Tasks</a>
+    </div>
+
+    <script>
+{{template "theme-script"}}
+    </script>
+</body>
+</html>`
+
+// handleSearch renders the notes matching the q query parameter
+func handleSearch(cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := SearchPageData{Query: r.URL.Query().Get("q")}
+
+		query, err := search.ParseQuery(data.Query)
+		if err != nil {
+			data.Error = err.Error()
+			renderPage(w, "search", data)
+			return
+		}
+		if query.Empty() {
+			renderPage(w, "search", data)
+			return
+		}
+
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		results := v.idx.Search(query)
+		data.Count = len(results)
+		if len(results) > searchLimit {
+			results = results[:searchLimit]
+		}
+		data.Results = results
+
+		renderPage(w, "search", data)
+	}
+}
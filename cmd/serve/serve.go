@@ -3,14 +3,22 @@ package serve
 import (
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"time"
 
+	"github.com/travis-mark/salthaven/internal/events"
+	"github.com/travis-mark/salthaven/internal/gitlog"
+	"github.com/travis-mark/salthaven/internal/graph"
+	"github.com/travis-mark/salthaven/internal/ical"
 	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/photos"
+	"github.com/travis-mark/salthaven/internal/search"
 )
 
 // NoteEntry represents a markdown note with its metadata
@@ -18,8 +26,29 @@ type NoteEntry struct {
 	Path     string
 	FullPath string
 	Date     time.Time
+	End      time.Time // Last day of a multi-day note
 	Title    string
 	Content  string
+	// Zero-based line in the file where Content starts
+	ContentLine int
+	// Fingerprint of the file as read, so edits can detect concurrent changes
+	Hash string
+	Tags []string
+	// Which day of a multi-day note matched, or zero
+	DayNumber int
+	DayCount  int
+	// Date property that matched, when it is not the primary date property
+	Property string
+	// Distance from the reference date, such as "3 days later", for nearest-date fallbacks
+	Offset string
+	// Similar notes from any date, and the notes linking here
+	Related   []RelatedNote
+	Backlinks []RelatedNote
+}
+
+// Multiday reports whether the note covers more than one day
+func (n NoteEntry) Multiday() bool {
+	return markdown.DateRange{Start: n.Date, End: n.End}.Multiday()
 }
 
 const htmlTemplate = `<!DOCTYPE html>
@@ -28,320 +57,124 @@ const htmlTemplate = `<!DOCTYPE html>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>On This Day - {{.FormattedDate}}</title>
+    {{if not .Static}}
+    <link rel="alternate" type="application/atom+xml" title="On This Day" href="/feed.atom">
+    <link rel="alternate" type="application/rss+xml" title="On This Day" href="/feed.rss">
+    {{end}}
     <style>
-        :root {
-            --bg-primary: #f9f9f9;
-            --bg-secondary: white;
-            --text-primary: #333;
-            --text-secondary: #7f8c8d;
-            --text-tertiary: #95a5a6;
-            --text-accent: #2c3e50;
-            --text-content: #34495e;
-            --border-color: #eee;
-            --shadow: rgba(0,0,0,0.1);
-        }
-
-        @media (prefers-color-scheme: dark) {
-            :root {
-                --bg-primary: #1a1a1a;
-                --bg-secondary: #2d2d2d;
-                --text-primary: #e0e0e0;
-                --text-secondary: #b0b0b0;
-                --text-tertiary: #888;
-                --text-accent: #64b5f6;
-                --text-content: #d0d0d0;
-                --border-color: #444;
-                --shadow: rgba(0,0,0,0.3);
-            }
-        }
-
-        [data-theme="dark"] {
-            --bg-primary: #1a1a1a;
-            --bg-secondary: #2d2d2d;
-            --text-primary: #e0e0e0;
-            --text-secondary: #b0b0b0;
-            --text-tertiary: #888;
-            --text-accent: #64b5f6;
-            --text-content: #d0d0d0;
-            --border-color: #444;
-            --shadow: rgba(0,0,0,0.3);
-        }
-
-        [data-theme="light"] {
-            --bg-primary: #f9f9f9;
-            --bg-secondary: white;
-            --text-primary: #333;
-            --text-secondary: #7f8c8d;
-            --text-tertiary: #95a5a6;
-            --text-accent: #2c3e50;
-            --text-content: #34495e;
-            --border-color: #eee;
-            --shadow: rgba(0,0,0,0.1);
-        }
-
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, "Helvetica Neue", Arial, sans-serif;
-            line-height: 1.6;
-            max-width: 800px;
-            margin: 0 auto;
-            padding: 20px;
-            color: var(--text-primary);
-            background-color: var(--bg-primary);
-            transition: background-color 0.3s ease, color 0.3s ease;
-        }
-        .header {
-            text-align: center;
-            margin-bottom: 30px;
-            padding: 20px;
-            background: var(--bg-secondary);
-            border-radius: 8px;
-            box-shadow: 0 2px 4px var(--shadow);
-            position: relative;
-            transition: background-color 0.3s ease, box-shadow 0.3s ease;
-        }
-        .header h1 {
-            color: var(--text-accent);
-            margin: 0;
-            transition: color 0.3s ease;
-        }
-        .header p {
-            color: var(--text-secondary);
-            margin: 10px 0 0 0;
-            transition: color 0.3s ease;
-        }
-        .theme-toggle {
-            position: absolute;
-            top: 20px;
-            right: 20px;
-            background: none;
-            border: 2px solid var(--text-tertiary);
-            border-radius: 50%;
-            width: 40px;
-            height: 40px;
-            cursor: pointer;
-            font-size: 18px;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            transition: all 0.3s ease;
-            color: var(--text-tertiary);
-        }
-        .theme-toggle:hover {
-            border-color: var(--text-accent);
-            color: var(--text-accent);
-            transform: scale(1.1);
-        }
-        .note {
-            background: var(--bg-secondary);
-            margin: 20px 0;
-            padding: 20px;
-            border-radius: 8px;
-            box-shadow: 0 2px 4px var(--shadow);
-            transition: background-color 0.3s ease, box-shadow 0.3s ease;
-        }
-        .note-header {
-            border-bottom: 1px solid var(--border-color);
-            padding-bottom: 10px;
-            margin-bottom: 15px;
-            transition: border-color 0.3s ease;
-        }
-        .note-title {
-            font-size: 1.3em;
-            font-weight: bold;
-            color: var(--text-accent);
-            margin: 0;
-            transition: color 0.3s ease;
-        }
-        .note-title-link {
-            color: var(--text-accent);
-            text-decoration: none;
-            transition: all 0.3s ease;
-        }
-        .note-title-link:hover {
-            text-decoration: underline;
-            opacity: 0.8;
-        }
-        .note-date {
-            color: var(--text-secondary);
-            font-size: 0.9em;
-            margin: 5px 0;
-            transition: color 0.3s ease;
-        }
-        .note-path {
-            color: var(--text-tertiary);
-            font-size: 0.8em;
-            font-family: monospace;
-            transition: color 0.3s ease;
-        }
-        .note-content {
-            white-space: pre-wrap;
-            color: var(--text-content);
-            transition: color 0.3s ease;
-        }
-        .no-notes {
-            text-align: center;
-            color: var(--text-secondary);
-            font-style: italic;
-            padding: 40px;
-            background: var(--bg-secondary);
-            border-radius: 8px;
-            box-shadow: 0 2px 4px var(--shadow);
-            transition: all 0.3s ease;
-        }
-        .footer {
-            text-align: center;
-            margin-top: 30px;
-            padding: 20px;
-            color: var(--text-tertiary);
-            font-size: 0.9em;
-            transition: color 0.3s ease;
-        }
-        .footer a {
-            color: var(--text-accent);
-            text-decoration: none;
-            transition: color 0.3s ease;
-        }
-        .footer a:hover {
-            text-decoration: underline;
-        }
-        
-        /* Checkbox styling */
-        .checkbox-item {
-            display: flex;
-            align-items: flex-start;
-            margin: 2px 0;
-            line-height: 1.5;
-        }
-        .checkbox {
-            width: 12px;
-            height: 12px;
-            margin-right: 6px;
-            margin-top: 3px;
-            border: 1px solid var(--text-tertiary);
-            border-radius: 2px;
-            background: var(--bg-secondary);
-            flex-shrink: 0;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            transition: background-color 0.2s ease;
-        }
-        .checkbox.checked {
-            background: var(--text-accent);
-            border-color: var(--text-accent);
-        }
-        .checkbox.checked::after {
-            content: '✓';
-            color: var(--bg-secondary);
-            font-size: 9px;
-            font-weight: bold;
-            line-height: 1;
-        }
-        .checkbox-text {
-            flex: 1;
-            color: var(--text-content);
-        }
-        
-        /* Link styling */
-        .content-link {
-            color: var(--text-accent);
-            text-decoration: none;
-            transition: all 0.2s ease;
-        }
-        .content-link:hover {
-            text-decoration: underline;
-            opacity: 0.8;
-        }
-        .obsidian-link {
-            color: var(--text-accent);
-            text-decoration: none;
-            transition: all 0.2s ease;
-        }
-        .obsidian-link:hover {
-            text-decoration: underline;
-            opacity: 0.8;
-        }
+{{template "style"}}
     </style>
 </head>
 <body>
     <div class="header">
-        <button class="theme-toggle" onclick="toggleTheme()" title="Toggle dark/light mode">
-            <span class="theme-icon">🌙</span>
-        </button>
-        <h1>On This Day</h1>
-        <p>{{.FormattedDate}} • {{.Count}} {{if eq .Count 1}}entry{{else}}entries{{end}} found</p>
+{{template "theme-toggle"}}
+        {{if .NoteView}}
+        <h1>Note</h1>
+        <p>{{.FormattedDate}} • <a href="/" class="content-link">On This Day</a></p>
+        {{else}}
+        <h1>{{.Heading}}</h1>
+        <p>{{.FormattedDate}} • {{if .Nearest}}nothing on this day, {{.Count}} nearby {{if eq .Count 1}}entry{{else}}entries{{end}}{{else}}{{.Count}} {{if eq .Count 1}}entry{{else}}entries{{end}} found{{end}}{{if not .DefaultMatch}} • <code>{{.Match}}</code>{{end}}</p>
+        {{if not .Static}}
+{{template "search-box" ""}}
+        {{end}}
+        {{end}}
     </div>
 
-    {{if .Notes}}
-        {{range .Notes}}
-        <div class="note">
-            <div class="note-header">
-                {{if .Title}}
-                <h2 class="note-title">
-                    <a href="obsidian://open?path={{.FullPath}}" class="note-title-link">{{.Title}}</a>
-                </h2>
-                {{end}}
-                <div class="note-date">{{.Date.Format "January 2, 2006"}}</div>
-                <div class="note-path">{{.Path}}</div>
-            </div>
-            <div class="note-content">{{.Content}}</div>
-        </div>
+    {{if .Tags}}
+    <div class="tag-chips">
+        {{range .Tags}}
+        <span class="tag-chip{{if .Included}} included{{end}}{{if .Excluded}} excluded{{end}}">
+            <a href="{{.IncludeURL}}" title="Only show notes tagged #{{.Name}}">#{{.Name}}{{if .Count}} <span class="tag-count">{{.Count}}</span>{{end}}</a>
+            <a href="{{.ExcludeURL}}" class="tag-exclude" title="Hide notes tagged #{{.Name}}">{{if .Excluded}}+{{else}}−{{end}}</a>
+        </span>
         {{end}}
-    {{else}}
-        <div class="no-notes">
-            No notes found for this day
-        </div>
+    </div>
     {{end}}
 
-    <div class="footer">
-        Generated by Salthaven • <a href="javascript:location.reload()">Refresh</a>
+    {{if .Events}}
+    <div class="events">
+        {{range .Events}}
+        <div class="event">
+            <span class="event-icon">{{.Icon}}</span>
+            <span class="event-title">{{.Title}}</span>
+            <span class="event-years">— {{.YearsLabel}}</span>
+            <div class="event-date">{{.Date.Format "January 2, 2006"}}</div>
+        </div>
+        {{end}}
     </div>
+    {{end}}
 
-    <script>
-        // Theme management
-        function getStoredTheme() {
-            return localStorage.getItem('theme');
-        }
+    {{if .Calendar}}
+    <div class="events">
+        {{range .Calendar}}
+        <div class="event">
+            <span class="event-icon">{{.Icon}}</span>
+            <span class="event-title">{{.Title}}</span>
+            <span class="event-years">— {{.YearsLabel}}</span>
+            <div class="event-date">{{.When}}{{if .Location}} • {{.Location}}{{end}}</div>
+        </div>
+        {{end}}
+    </div>
+    {{end}}
 
-        function setStoredTheme(theme) {
-            localStorage.setItem('theme', theme);
-        }
+    {{if .Commits}}
+    <div class="events">
+        {{range .Commits}}
+        <div class="event">
+            <span class="event-icon">{{.Icon}}</span>
+            <span class="event-title">{{.Repo}}: {{.Subject}}</span>
+            <span class="event-years">— {{.YearsLabel}}</span>
+            <div class="event-date">{{.Date.Format "January 2, 2006 3:04 PM"}} • <code>{{.ShortHash}}</code></div>
+        </div>
+        {{end}}
+    </div>
+    {{end}}
 
-        function getPreferredTheme() {
-            const storedTheme = getStoredTheme();
-            if (storedTheme) {
-                return storedTheme;
-            }
-            return window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
-        }
+    {{if .Groups}}
+        {{range .Groups}}
+        <h2 class="year-heading">{{.Year}}</h2>
+        {{range .Notes}}{{template "note" .}}{{end}}
+        {{end}}
+    {{else if .Notes}}
+        {{range .Notes}}{{template "note" .}}{{end}}
+    {{else if not (or .Events .Calendar .Photos .Commits)}}
+        <div class="no-notes">
+            No notes found for this {{.Period}}{{if .Random}} — here is a random memory instead{{end}}
+        </div>
+        {{with .Random}}
+        {{template "note" .}}
+        {{if not $.Static}}<div class="random-more"><a href="/random?older=1" class="content-link">Another random memory</a></div>{{end}}
+        {{end}}
+    {{end}}
 
-        function setTheme(theme) {
-            document.documentElement.setAttribute('data-theme', theme);
-            const themeIcon = document.querySelector('.theme-icon');
-            if (themeIcon) {
-                themeIcon.textContent = theme === 'dark' ? '☀️' : '🌙';
-            }
-        }
+    {{if .Photos}}
+    <div class="photos">
+        {{range .Photos}}
+        <h2 class="year-heading">📷 {{.Year}}</h2>
+        <div class="photo-grid">
+            {{range .Photos}}
+            <a href="{{.URL}}" target="_blank" rel="noopener" title="{{.Name}} — {{.When}}">
+                <img src="{{.ThumbURL}}" alt="{{.Name}}" loading="lazy">
+            </a>
+            {{end}}
+        </div>
+        {{end}}
+    </div>
+    {{end}}
 
-        function toggleTheme() {
-            const currentTheme = document.documentElement.getAttribute('data-theme');
-            const newTheme = currentTheme === 'dark' ? 'light' : 'dark';
-            setTheme(newTheme);
-            setStoredTheme(newTheme);
-        }
+    <div class="footer">
+        {{if .Static}}
+        Generated by Salthaven • <a href="/calendar">Calendar</a>
+        {{else}}
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/calendar">Calendar</a> • <a href="/week">Week</a> • <a href="/month">Month</a> • <a href="/tasks">Tasks</a> • <a href="/search">Search</a> • <a href="/random">Random</a> • <a href="/stats">Stats</a> • <a href="/heatmap">Heatmap</a> • <a href="javascript:location.reload()">Refresh</a>
+        {{end}}
+    </div>
 
-        // Initialize theme on page load
-        document.addEventListener('DOMContentLoaded', function() {
-            const preferredTheme = getPreferredTheme();
-            setTheme(preferredTheme);
-        });
+    <script>
+{{template "theme-script"}}
 
-        // Listen for system theme changes
-        window.matchMedia('(prefers-color-scheme: dark)').addEventListener('change', function(e) {
-            if (!getStoredTheme()) {
-                setTheme(e.matches ? 'dark' : 'light');
-            }
-        });
+        // Exported pages have no server to save task changes to
+        const staticSite = {{.Static}};
 
         // Link conversion functions
         function convertMarkdownLinks(text) {
@@ -373,45 +206,98 @@ const htmlTemplate = `<!DOCTYPE html>
         }
 
         // Checkbox functionality
+        function checkboxHTML(indent, checked, line, text) {
+            const state = checked ? ' checked' : '';
+            return indent + '<div class="checkbox-item' + state + '">' +
+                   '<div class="checkbox' + state + '" role="checkbox" tabindex="0"' +
+                   ' aria-checked="' + checked + '" data-line="' + line + '"></div>' +
+                   '<span class="checkbox-text">' + convertLinks(text) + '</span>' +
+                   '</div>';
+        }
+
         function convertCheckboxes() {
             const noteContents = document.querySelectorAll('.note-content');
-            
+
             noteContents.forEach(function(content) {
-                let html = content.innerHTML;
-                
-                // Convert unchecked checkboxes: - [ ] or * [ ] 
-                html = html.replace(/^(\s*)([-*])\s+\[\s\]\s+(.+)$/gm, function(match, indent, bullet, text) {
-                    const convertedText = convertLinks(text);
-                    return indent + '<div class="checkbox-item">' +
-                           '<div class="checkbox"></div>' +
-                           '<span class="checkbox-text">' + convertedText + '</span>' +
-                           '</div>';
-                });
-                
-                // Convert checked checkboxes: - [x] or * [x]
-                html = html.replace(/^(\s*)([-*])\s+\[[xX]\]\s+(.+)$/gm, function(match, indent, bullet, text) {
-                    const convertedText = convertLinks(text);
-                    return indent + '<div class="checkbox-item checked">' +
-                           '<div class="checkbox checked"></div>' +
-                           '<span class="checkbox-text">' + convertedText + '</span>' +
-                           '</div>';
-                });
-                
-                // Convert links in remaining text (non-checkbox lines)
-                const lines = html.split('\n');
-                const processedLines = lines.map(function(line) {
-                    // Skip lines that are already converted to checkbox HTML
-                    if (line.includes('checkbox-item')) {
-                        return line;
+                // Line in the source file where the displayed content starts
+                const firstLine = parseInt(content.dataset.line || '0', 10);
+
+                // Convert checkboxes - [ ] / * [ ] and - [x] / * [x], and links in remaining lines
+                const lines = content.innerHTML.split('\n');
+                const processedLines = lines.map(function(line, index) {
+                    const match = line.match(/^(\s*)[-*]\s+\[([ xX])\]\s+(.+)$/);
+                    if (match) {
+                        return checkboxHTML(match[1], match[2] !== ' ', firstLine + index, match[3]);
                     }
                     return convertLinks(line);
                 });
-                html = processedLines.join('\n');
-                
-                content.innerHTML = html;
+
+                content.innerHTML = processedLines.join('\n');
             });
         }
 
+        function setCheckboxState(box, checked) {
+            box.classList.toggle('checked', checked);
+            box.parentElement.classList.toggle('checked', checked);
+            box.setAttribute('aria-checked', checked);
+        }
+
+        // Flip a task in the source file; the hash lets the server refuse if the file changed meanwhile
+        function toggleTask(box) {
+            const content = box.closest('.note-content');
+            if (staticSite || !content || box.classList.contains('pending')) {
+                return;
+            }
+
+            const wasChecked = box.classList.contains('checked');
+            setCheckboxState(box, !wasChecked);
+            box.classList.add('pending');
+
+            fetch('/api/v1/tasks/toggle', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    path: content.dataset.path,
+                    line: parseInt(box.dataset.line, 10),
+                    hash: content.dataset.hash
+                })
+            }).then(function(response) {
+                return response.json().then(function(body) {
+                    if (!response.ok) {
+                        throw { status: response.status, message: body.error };
+                    }
+                    return body;
+                });
+            }).then(function(body) {
+                content.dataset.hash = body.hash;
+                setCheckboxState(box, body.checked);
+            }).catch(function(err) {
+                setCheckboxState(box, wasChecked);
+                if (err.status === 409) {
+                    alert('This note was changed on disk since the page was loaded. Refresh to see the latest version.');
+                } else {
+                    alert('Could not update task: ' + (err.message || err));
+                }
+            }).finally(function() {
+                box.classList.remove('pending');
+            });
+        }
+
+        document.addEventListener('click', function(e) {
+            const box = e.target.closest('.checkbox[data-line]');
+            if (box) {
+                toggleTask(box);
+            }
+        });
+
+        document.addEventListener('keydown', function(e) {
+            const box = e.target.closest && e.target.closest('.checkbox[data-line]');
+            if (box && (e.key === ' ' || e.key === 'Enter')) {
+                e.preventDefault();
+                toggleTask(box);
+            }
+        });
+
         // Initialize checkboxes after theme is set
         document.addEventListener('DOMContentLoaded', function() {
             const preferredTheme = getPreferredTheme();
@@ -422,56 +308,54 @@ const htmlTemplate = `<!DOCTYPE html>
         });
     </script>
 </body>
-</html>`
-
-// extractTitleFromContent extracts title from markdown content
-func extractTitleFromContent(content string) string {
-	lines := splitLines(content)
-	inFrontmatter := false
-	foundFirstContentLine := false
+</html>
 
-	for i, line := range lines {
-		line = trimSpace(line)
-
-		if i == 0 && line == "---" {
-			inFrontmatter = true
-			continue
-		}
-
-		if inFrontmatter && line == "---" {
-			inFrontmatter = false
-			continue
-		}
-
-		if inFrontmatter {
-			if startsWithTitle(line) {
-				return extractTitleValue(line)
-			}
-		} else {
-			// Only check the first non-empty content line for markdown header
-			if !foundFirstContentLine && line != "" {
-				foundFirstContentLine = true
-				if startsWithHash(line) {
-					return trimLeadingHashes(line)
-				}
-			}
-		}
-	}
-
-	return ""
-}
+{{define "note"}}
+        <div class="note">
+            <div class="note-header">
+                {{if .Title}}
+                <h2 class="note-title">
+                    {{if .FullPath}}<a href="obsidian://open?path={{.FullPath}}" class="note-title-link">{{.Title}}</a>{{else}}{{.Title}}{{end}}
+                </h2>
+                {{end}}
+                <div class="note-date">
+                    {{.Date.Format "January 2, 2006"}}{{if .Multiday}} – {{.End.Format "January 2, 2006"}}{{end}}
+                    {{if .Property}}<span class="note-day">{{.Property}}</span>{{end}}
+                    {{if .DayNumber}}<span class="note-day">Day {{.DayNumber}} of {{.DayCount}}</span>{{end}}
+                    {{if .Offset}}<span class="note-day">{{.Offset}}</span>{{end}}
+                </div>
+                <a href="/note?path={{.Path}}" class="note-path">{{.Path}}</a>
+                {{if .Tags}}
+                <div class="note-tags">{{range .Tags}}<span class="note-tag">#{{.}}</span> {{end}}</div>
+                {{end}}
+            </div>
+            <div class="note-content" data-path="{{.Path}}" data-line="{{.ContentLine}}" data-hash="{{.Hash}}">{{.Content}}</div>
+            {{if .Backlinks}}
+            <div class="note-related">
+                Linked from
+                {{range $i, $note := .Backlinks}}{{if $i}} • {{end}}<a href="/note?path={{$note.Path}}">{{$note.Title}}</a>{{if not $note.Date.IsZero}} <span class="note-related-date">({{$note.Date.Format "2006"}})</span>{{end}}{{end}}
+            </div>
+            {{end}}
+            {{if .Related}}
+            <div class="note-related">
+                You also wrote about this in
+                {{range $i, $note := .Related}}{{if $i}} • {{end}}<a href="/note?path={{$note.Path}}">{{$note.Title}}</a>{{if not $note.Date.IsZero}} <span class="note-related-date">({{$note.Date.Format "2006"}})</span>{{end}}{{end}}
+            </div>
+            {{end}}
+        </div>
+{{end}}`
 
 // Helper functions to avoid regex
+
+// splitLines splits s at each \n, dropping carriage returns, so a CRLF ends
+// one line and lines are numbered as ToggleTask numbers them
 func splitLines(s string) []string {
 	var lines []string
 	var current string
-	for i, c := range s {
+	for _, c := range s {
 		if c == '\n' {
 			lines = append(lines, current)
 			current = ""
-		} else if c == '\r' && i+1 < len(s) && s[i+1] == '\n' {
-			lines = append(lines, current)
-			current = ""
 		} else if c != '\r' {
 			current += string(c)
 		}
@@ -501,71 +385,12 @@ func isSpace(c byte) bool {
 	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
 
-func startsWithTitle(line string) bool {
-	return len(line) >= 6 && line[:6] == "title:"
-}
-
-func extractTitleValue(line string) string {
-	if len(line) <= 6 {
-		return ""
-	}
-	value := trimSpace(line[6:])
-	if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'')) {
-		return value[1 : len(value)-1]
-	}
-	return value
-}
-
-func startsWithHash(line string) bool {
-	return len(line) > 0 && line[0] == '#'
-}
-
-func trimLeadingHashes(line string) string {
-	i := 0
-	for i < len(line) && line[i] == '#' {
-		i++
-	}
-	if i < len(line) && line[i] == ' ' {
-		i++
-	}
-	return line[i:]
-}
-
-// getContentWithoutFrontmatter removes YAML frontmatter from content
-func getContentWithoutFrontmatter(content string) string {
-	lines := splitLines(content)
-	if len(lines) == 0 {
-		return content
-	}
-
-	if trimSpace(lines[0]) != "---" {
-		return content
-	}
-
-	for i := 1; i < len(lines); i++ {
-		if trimSpace(lines[i]) == "---" {
-			if i+1 < len(lines) {
-				var result string
-				for j := i + 1; j < len(lines); j++ {
-					if j > i+1 {
-						result += "\n"
-					}
-					result += lines[j]
-				}
-				return result
-			}
-			return ""
-		}
-	}
-
-	return content
-}
-
-// getContentWithoutFrontmatterAndTitle removes YAML frontmatter and first-line title from content
-func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) string {
+// getContentWithoutFrontmatterAndTitle removes YAML frontmatter and first-line title from content.
+// It also returns the zero-based line of the original file that the result starts at.
+func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) (string, int) {
 	lines := splitLines(content)
 	if len(lines) == 0 {
-		return content
+		return content, 0
 	}
 
 	startIndex := 0
@@ -586,7 +411,7 @@ func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) string
 			line := trimSpace(lines[i])
 			if line != "" {
 				// Check if this line is a header that matches our extracted title
-				if startsWithHash(line) && trimLeadingHashes(line) == extractedTitle {
+				if heading, ok := markdown.Heading(line); ok && heading == extractedTitle {
 					startIndex = i + 1
 				}
 				break
@@ -596,7 +421,7 @@ func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) string
 
 	// Build result from remaining lines
 	if startIndex >= len(lines) {
-		return ""
+		return "", startIndex
 	}
 
 	// Skip any empty lines at the beginning of content
@@ -605,7 +430,7 @@ func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) string
 	}
 
 	if startIndex >= len(lines) {
-		return ""
+		return "", startIndex
 	}
 
 	var result string
@@ -616,105 +441,470 @@ func getContentWithoutFrontmatterAndTitle(content, extractedTitle string) string
 		result += lines[j]
 	}
 
-	return result
+	return result, startIndex
+}
+
+// NoteGroup holds the notes of one year in a digest
+type NoteGroup struct {
+	Year  int
+	Notes []NoteEntry
 }
 
 // PageData represents the data passed to the HTML template
 type PageData struct {
 	Notes         []NoteEntry
+	Groups        []NoteGroup // Notes grouped by year, for digests
+	Heading       string
+	Period        string // "day", "week" or "month", for the empty state
 	FormattedDate string
 	Count         int
+	NoteView      bool // Page shows a single note rather than a day
+	Tags          []TagChip
+	Events        []events.Occurrence
+	Calendar      []ical.Occurrence // Past events from the vault's calendar files
+	Photos        []PhotoYear       // Photos taken on the day in earlier years
+	Commits       []gitlog.Occurrence
+	Random        *NoteEntry // Shown when nothing matches
+	Nearest       bool       // Notes are the closest to the date, as none fall on it
+	Match         string     // Match expression that selected the notes
+	DefaultMatch  bool       // Match is the plain same-day expression
+	Static        bool       // Exported as a static site, without the server's dynamic pages
 }
 
-// Execute runs the serve command
-func Execute(folderPath string, verbose bool, port int) error {
-	// Check if folder exists
-	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
-		return fmt.Errorf("folder does not exist: %s", folderPath)
+// loadNoteEntry reads a note and extracts the metadata shown on the page
+func loadNoteEntry(folderPath, path string, dateProperties []string) (NoteEntry, error) {
+	content, err := markdown.ReadFileContent(path)
+	if err != nil {
+		return NoteEntry{}, fmt.Errorf("could not read file %s: %v", path, err)
 	}
 
-	// Set up HTTP handler
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// Get notes for today using the same logic as onthisday
-		today := time.Now()
-		notePaths, err := markdown.ScanMarkdownNotes(folderPath, markdown.SameDayMatcher, today, verbose)
+	dates, err := markdown.ParseYAMLDates(content, dateProperties)
+	if err != nil {
+		return NoteEntry{}, fmt.Errorf("could not parse date from %s: %v", path, err)
+	}
+
+	return newNoteEntry(folderPath, markdown.Note{
+		Path:    path,
+		Content: content,
+		Date:    dates[0].Start,
+		End:     dates[0].End,
+		Dates:   dates,
+		Tags:    markdown.ParseTags(content),
+	}), nil
+}
+
+// newMatchEntry builds the page entry for a note selected for a reference date,
+// showing the dates of the property that matched
+func newMatchEntry(folderPath string, match markdown.Match) NoteEntry {
+	entry := newNoteEntry(folderPath, match.Note)
+	entry.Date = match.Source.Start
+	entry.End = match.Source.End
+	if match.Source.Property != markdown.PrimaryDateProperty {
+		entry.Property = match.Source.Property
+	}
+	if match.DayCount() > 1 {
+		entry.DayNumber = match.DayNumber()
+		entry.DayCount = match.DayCount()
+	}
+	return entry
+}
+
+// newNoteEntry extracts the metadata shown on the page from a scanned note
+func newNoteEntry(folderPath string, note markdown.Note) NoteEntry {
+	title := markdown.ContentTitle(note.Content)
+	cleanContent, contentLine := getContentWithoutFrontmatterAndTitle(note.Content, title)
+
+	// Get relative path for display
+	relPath, err := filepath.Rel(folderPath, note.Path)
+	if err != nil {
+		relPath = note.Path
+	}
+
+	// Get absolute path for Obsidian link
+	fullPath, err := filepath.Abs(note.Path)
+	if err != nil {
+		fullPath = note.Path
+	}
+
+	return NoteEntry{
+		Path:        relPath,
+		FullPath:    fullPath,
+		Date:        note.Date,
+		End:         note.End,
+		Title:       title,
+		Content:     cleanContent,
+		ContentLine: contentLine,
+		Hash:        markdown.ContentHash(note.Content),
+		Tags:        note.Tags,
+	}
+}
+
+// pageTemplates lists each page template by name
+var pageTemplates = map[string]string{
+	"onthisday": htmlTemplate,
+	"tasks":     tasksTemplate,
+	"search":    searchTemplate,
+	"stats":     statsTemplate,
+	"heatmap":   heatmapTemplate,
+	"review":    reviewTemplate,
+	"calendar":  calendarTemplate,
+}
+
+// parsePage parses the named page template together with the shared layout
+func parsePage(name string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(layoutTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(pageTemplates[name])
+}
+
+// renderPage executes the named page template with data
+func renderPage(w http.ResponseWriter, name string, data interface{}) {
+	tmpl, err := parsePage(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("Template execution error: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// digestHeadings titles the digest pages
+var digestHeadings = map[markdown.Digest]string{
+	markdown.DigestWeek:  "On This Week",
+	markdown.DigestMonth: "On This Month",
+}
+
+// vault is a scan of every note with the indexes built from it, shared by the pages
+type vault struct {
+	notes    []markdown.Note // Every note, dated or not
+	dated    []markdown.Note
+	idx      *search.Index
+	links    *graph.Graph
+	events   []events.Event
+	calendar []ical.Event // Events from the vault's .ics files
+	photos   []photos.Photo
+	commits  []gitlog.Commit
+}
+
+// loadVault scans the notes and builds the search index and link graph, then
+// reads the photos and commits
+func loadVault(folderPath string, opts Options) (vault, error) {
+	v, err := scanVault(folderPath, opts)
+	if err != nil {
+		return vault{}, err
+	}
+	v.photos = loadPhotos(folderPath, opts)
+	v.commits = loadCommits(folderPath, opts)
+	return v, nil
+}
+
+// scanVault scans the notes, events and calendar files, and builds the search
+// index and link graph
+func scanVault(folderPath string, opts Options) (vault, error) {
+	notes, err := markdown.ScanNotes(folderPath, opts.DateProperties, opts.Verbose)
+	if err != nil {
+		return vault{}, err
+	}
+	v := vault{
+		notes: notes,
+		dated: markdown.DatedNotes(notes, opts.Verbose),
+		idx:   search.Build(folderPath, notes),
+		links: graph.Build(folderPath, notes),
+	}
+
+	// Recurring events are matched like notes
+	v.events, err = events.Load(folderPath, opts.EventsFile, opts.PeopleFolder)
+	if err != nil && opts.Verbose {
+		fmt.Printf("Warning: Could not load events: %v\n", err)
+	}
+	v.calendar, err = ical.ScanFolder(folderPath, opts.Verbose)
+	if err != nil && opts.Verbose {
+		fmt.Printf("Warning: Could not load calendars: %v\n", err)
+	}
+	return v, nil
+}
+
+// loadPhotos scans the photos folder, if one is configured
+func loadPhotos(folderPath string, opts Options) []photos.Photo {
+	folder := photosFolder(folderPath, opts)
+	if folder == "" {
+		return nil
+	}
+	scanned, err := photos.Scan(folder, opts.Verbose)
+	if err != nil && opts.Verbose {
+		fmt.Printf("Warning: Could not scan photos: %v\n", err)
+	}
+	return scanned
+}
+
+// loadCommits reads the configured repositories' commits
+func loadCommits(folderPath string, opts Options) []gitlog.Commit {
+	commits, err := gitlog.LoadAll(folderPath, opts.GitRepos, opts.GitAuthor)
+	if err != nil && opts.Verbose {
+		fmt.Printf("Warning: Could not read git history: %v\n", err)
+	}
+	return commits
+}
+
+// handleOnThisDay renders the notes matching today, or a week or month digest
+func handleOnThisDay(folderPath string, opts Options, cache *vaultCache, digest markdown.Digest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		matchExpr := opts.Match
+		if query.Get("match") != "" {
+			matchExpr = query.Get("match")
+		}
+		matcher, err := markdown.ParseMatcher(matchExpr, opts.LeapDay)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Process each note to extract metadata and content
-		var notes []NoteEntry
-		for _, path := range notePaths {
-			content, err := markdown.ReadFileContent(path)
+		// Get notes for today, or the date asked for, using the same logic as onthisday
+		today := time.Now()
+		formattedDate := today.Format("Monday, January 2")
+		if value := query.Get("date"); value != "" {
+			date, err := time.ParseInLocation("2006-01-02", value, time.Local)
 			if err != nil {
-				if verbose {
-					fmt.Printf("Warning: Could not read file %s: %v\n", path, err)
-				}
-				continue
+				http.Error(w, fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", value), http.StatusBadRequest)
+				return
 			}
+			today = date
+			formattedDate = date.Format("Monday, January 2, 2006")
+		}
+		v, err := cache.load()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
 
-			fileDate, err := markdown.ParseYAMLDate(content)
-			if err != nil {
-				if verbose {
-					fmt.Printf("Warning: Could not parse date from %s: %v\n", path, err)
-				}
-				continue
-			}
+		data := dayPage(folderPath, v, opts, dayQuery{
+			Matcher:       matcher,
+			MatchExpr:     matchExpr,
+			Digest:        digest,
+			Date:          today,
+			FormattedDate: formattedDate,
+			Tags:          tagFilterFromQuery(query),
+			Query:         query,
+		})
+		renderPage(w, "onthisday", data)
+	}
+}
+
+// dayQuery is what a day or digest page shows
+type dayQuery struct {
+	Matcher       markdown.DateMatcher
+	MatchExpr     string
+	Digest        markdown.Digest
+	Date          time.Time
+	FormattedDate string
+	Tags          markdown.TagFilter
+	Query         url.Values // For the tag chip links; nil leaves out the chips
+}
 
-			title := extractTitleFromContent(content)
-			cleanContent := getContentWithoutFrontmatterAndTitle(content, title)
+// dayPage gathers the notes and events matching a date, or a week or month digest
+func dayPage(folderPath string, v vault, opts Options, q dayQuery) PageData {
+	matcher := q.Matcher
+	if q.Digest != markdown.DigestNone {
+		matcher = q.Digest.Matcher()
+	}
 
-			// Get relative path for display
-			relPath, err := filepath.Rel(folderPath, path)
-			if err != nil {
-				relPath = path
-			}
+	// Offer a chip for every tag on the day's notes, then apply the tag filter
+	dayNotes := markdown.SelectNotes(v.dated, matcher, q.Date, markdown.TagFilter{})
+	var chips []TagChip
+	if q.Query != nil {
+		chips = tagChips(q.Query, dayNotes, q.Tags)
+	}
 
-			// Get absolute path for Obsidian link
-			fullPath, err := filepath.Abs(path)
-			if err != nil {
-				fullPath = path
+	// Process each note to extract metadata and content
+	var selected []markdown.Match
+	var notes []NoteEntry
+	for _, note := range dayNotes {
+		if q.Tags.Matches(note.Tags) {
+			selected = append(selected, note)
+			notes = append(notes, newMatchEntry(folderPath, note))
+		}
+	}
+
+	// Sort notes by date, newest first
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Date.After(notes[j].Date)
+	})
+
+	// Relate each note to similar ones from any date, and to the notes linking to it
+	addRelated(v.idx, notes)
+	addBacklinks(v.links, notes)
+
+	data := PageData{
+		Events:        events.Select(v.events, matcher, q.Date),
+		Calendar:      ical.Select(v.calendar, matcher, q.Date),
+		Commits:       gitlog.Select(v.commits, matcher, q.Date),
+		Photos:        photoGallery(photosFolder(folderPath, opts), photos.Select(v.photos, matcher, q.Date)),
+		Notes:         notes,
+		Heading:       "On This Day",
+		Period:        "day",
+		FormattedDate: q.FormattedDate,
+		Count:         len(notes),
+		Tags:          chips,
+		Match:         q.MatchExpr,
+		DefaultMatch:  q.MatchExpr == markdown.DefaultMatchExpr,
+	}
+
+	// Digests group the period's notes by year instead
+	if q.Digest != markdown.DigestNone {
+		data.Heading = digestHeadings[q.Digest]
+		data.Period = string(q.Digest)
+		data.FormattedDate = q.Digest.Label(q.Date)
+		data.DefaultMatch = true
+		for _, group := range markdown.GroupByYear(selected, q.Digest.Year) {
+			noteGroup := NoteGroup{Year: group.Year}
+			for _, note := range group.Notes {
+				noteGroup.Notes = append(noteGroup.Notes, newMatchEntry(folderPath, note))
 			}
+			addRelated(v.idx, noteGroup.Notes)
+			addBacklinks(v.links, noteGroup.Notes)
+			data.Groups = append(data.Groups, noteGroup)
+		}
+	}
 
-			notes = append(notes, NoteEntry{
-				Path:     relPath,
-				FullPath: fullPath,
-				Date:     fileDate,
-				Title:    title,
-				Content:  cleanContent,
-			})
+	// Fall back to the notes dated closest to the day
+	if len(data.Notes) == 0 && q.Digest == markdown.DigestNone && opts.Nearest > 0 {
+		for _, note := range markdown.SelectNearest(v.dated, opts.Nearest, q.Date, q.Tags) {
+			entry := newMatchEntry(folderPath, note.Match)
+			entry.Offset = note.OffsetLabel()
+			data.Notes = append(data.Notes, entry)
 		}
+		addRelated(v.idx, data.Notes)
+		addBacklinks(v.links, data.Notes)
+		data.Count = len(data.Notes)
+		data.Nearest = len(data.Notes) > 0
+	}
 
-		// Sort notes by date, newest first
-		sort.Slice(notes, func(i, j int) bool {
-			return notes[i].Date.After(notes[j].Date)
-		})
+	// Rather than an empty page, offer a random memory, favouring older ones
+	if len(data.Notes) == 0 && len(data.Groups) == 0 && len(data.Events) == 0 && len(data.Calendar) == 0 && len(data.Photos) == 0 && len(data.Commits) == 0 {
+		randomOpts := markdown.RandomOptions{Tags: q.Tags, WeightOlder: true}
+		if note, ok := markdown.PickRandom(v.dated, randomOpts, q.Date); ok {
+			entry := newNoteEntry(folderPath, note)
+			data.Random = &entry
+		}
+	}
+
+	return data
+}
+
+// notePage shows a single note with the notes similar to it and linking to it
+func notePage(v vault, note NoteEntry) PageData {
+	notes := []NoteEntry{note}
+	addRelated(v.idx, notes)
+	addBacklinks(v.links, notes)
+
+	return PageData{
+		Notes:         notes,
+		FormattedDate: note.Date.Format("Monday, January 2, 2006"),
+		Count:         1,
+		NoteView:      true,
+	}
+}
+
+// Options configures the serve command
+type Options struct {
+	Verbose bool
+	Host    string // Interface to listen on; loopback unless other machines should reach the server
+	Port    int
+	Match   string // Default match expression, overridable with ?match=
+	LeapDay markdown.LeapDayPolicy
+	// Frontmatter properties holding dates, each matched independently
+	DateProperties []string
+	// Recurring events from a YAML/CSV file and a folder of person notes
+	EventsFile   string
+	PeopleFolder string
+	// Folder of place notes, for the year in review
+	PlacesFolder string
+	// Folder of photos, such as a synced camera roll, shown by the day they were taken
+	PhotosFolder string
+	// Local git repositories whose commits from the day are shown, and whose
+	// commits count; empty means each repository's configured user.email
+	GitRepos  []string
+	GitAuthor string
+	// When no notes match, show the closest within this many days instead; zero disables
+	Nearest int
+}
 
-		// Prepare template data
-		data := PageData{
-			Notes:         notes,
-			FormattedDate: today.Format("Monday, January 2"),
-			Count:         len(notes),
+// Execute runs the serve command
+func Execute(folderPath string, opts Options) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+	// Reject a bad default expression, events file or repository now rather than on every request
+	if _, err := markdown.ParseMatcher(opts.Match, opts.LeapDay); err != nil {
+		return err
+	}
+	if _, err := events.Load(folderPath, opts.EventsFile, opts.PeopleFolder); err != nil {
+		return err
+	}
+	if _, err := gitlog.LoadAll(folderPath, opts.GitRepos, opts.GitAuthor); err != nil {
+		return err
+	}
+
+	// The pages share one scan of the vault, made again when a note changes
+	cache := newVaultCache(folderPath, opts)
+
+	// Set up HTTP handlers
+	http.HandleFunc("/", handleOnThisDay(folderPath, opts, cache, markdown.DigestNone))
+	http.HandleFunc("/week", handleOnThisDay(folderPath, opts, cache, markdown.DigestWeek))
+	http.HandleFunc("/month", handleOnThisDay(folderPath, opts, cache, markdown.DigestMonth))
+
+	// Single note view, addressed by vault-relative path
+	http.HandleFunc("/note", func(w http.ResponseWriter, r *http.Request) {
+		path, err := resolveNotePath(folderPath, r.URL.Query().Get("path"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
 
-		// Parse and execute template
-		tmpl, err := template.New("onthisday").Parse(htmlTemplate)
+		note, err := loadNoteEntry(folderPath, path, opts.DateProperties)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
 
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if err := tmpl.Execute(w, data); err != nil {
-			http.Error(w, fmt.Sprintf("Template execution error: %v", err), http.StatusInternalServerError)
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
 			return
 		}
+		renderPage(w, "onthisday", notePage(v, note))
 	})
 
+	http.HandleFunc("/calendar", handleCalendar(opts, cache))
+	http.HandleFunc("/photo", handlePhoto(folderPath, opts))
+	http.HandleFunc("/feed.atom", handleFeed(folderPath, opts, cache, "atom"))
+	http.HandleFunc("/feed.rss", handleFeed(folderPath, opts, cache, "rss"))
+	http.HandleFunc("/calendar.ics", handleICS(folderPath, opts, cache))
+	http.HandleFunc("/tasks", handleTasks(folderPath, cache))
+	http.HandleFunc("/search", handleSearch(cache))
+	http.HandleFunc("/random", handleRandom(folderPath, cache))
+	http.HandleFunc("/stats", handleStats(folderPath, cache))
+	http.HandleFunc("/heatmap", handleHeatmap(cache))
+	http.HandleFunc("/review", handleReview(folderPath, opts, cache))
+	http.HandleFunc("/review/{year}", handleReview(folderPath, opts, cache))
+	http.HandleFunc("/api/v1/today/append", handleAppend(folderPath, opts))
+	http.HandleFunc("/api/v1/tasks/toggle", handleToggleTask(folderPath, opts))
+
 	// Start server
-	addr := ":" + strconv.Itoa(port)
-	fmt.Printf("Starting server on http://localhost%s\n", addr)
+	host := opts.Host
+	if host == "" {
+		host = DefaultHost
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(opts.Port))
+	fmt.Printf("Starting server on http://%s\n", addr)
 	fmt.Printf("Serving notes from: %s\n", folderPath)
 	fmt.Printf("Press Ctrl+C to stop\n")
 
@@ -0,0 +1,62 @@
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+func TestSplitLinesCRLF(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a\nb\nc", []string{"a", "b", "c"}},
+		{"a\r\nb\r\nc\r\n", []string{"a", "b", "c"}},
+		{"a\r\n\r\nb", []string{"a", "", "b"}},
+		{"a\n\nb\n", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		got := splitLines(tt.input)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestToggleTaskCRLF toggles a task the way the page does, counting from the
+// entry's ContentLine, in a note with Windows line endings
+func TestToggleTaskCRLF(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, "note.md")
+	content := strings.ReplaceAll("---\ndate: 2020-10-18\n---\n# Errands\n\n- [ ] Buy milk\n- [ ] Post letter\n", "\n", "\r\n")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := newNoteEntry(folder, markdown.Note{Path: path, Content: content})
+	index := -1
+	for i, line := range strings.Split(entry.Content, "\n") {
+		if strings.Contains(line, "Post letter") {
+			index = i
+		}
+	}
+	if index < 0 {
+		t.Fatalf("task not in displayed content %q", entry.Content)
+	}
+
+	if _, checked, err := markdown.ToggleTask(path, entry.ContentLine+index, entry.Hash); err != nil || !checked {
+		t.Fatalf("ToggleTask() = %v, %v; want checked", checked, err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(content, "- [ ] Post letter", "- [x] Post letter", 1)
+	if string(got) != want {
+		t.Errorf("note after toggle = %q, want %q", got, want)
+	}
+}
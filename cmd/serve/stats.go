@@ -0,0 +1,162 @@
+package serve
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// StatsPageData represents the data passed to the stats template
+type StatsPageData struct {
+	stats.Stats
+	YearChart    template.HTML
+	MonthChart   template.HTML
+	WeekdayChart template.HTML
+}
+
+const statsTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Journal Statistics</title>
+    <style>
+{{template "style"}}
+        .stat-cards {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
+            gap: 12px;
+            margin-bottom: 20px;
+        }
+        .stat-card {
+            background: var(--bg-secondary);
+            border-radius: 8px;
+            box-shadow: 0 2px 4px var(--shadow);
+            padding: 15px;
+            text-align: center;
+        }
+        .stat-value {
+            font-size: 1.6em;
+            font-weight: 600;
+            color: var(--text-accent);
+        }
+        .stat-label {
+            color: var(--text-secondary);
+            font-size: 0.85em;
+        }
+        .stat-detail {
+            color: var(--text-tertiary);
+            font-size: 0.75em;
+            margin-top: 4px;
+        }
+        .note h2 {
+            color: var(--text-accent);
+            font-size: 1.1em;
+            margin: 0 0 10px 0;
+        }
+        .chart-bar {
+            fill: var(--text-accent);
+        }
+        .chart-value, .chart-label {
+            fill: var(--text-secondary);
+            font-size: 10px;
+            font-family: inherit;
+        }
+        .stat-list {
+            margin: 0;
+            padding-left: 20px;
+            color: var(--text-content);
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>Journal Statistics</h1>
+        <p>{{.Notes}} notes • <a href="/" class="content-link">On This Day</a></p>
+    </div>
+
+    <div class="stat-cards">
+        <div class="stat-card">
+            <div class="stat-value">{{.Dated}}</div>
+            <div class="stat-label">dated notes</div>
+        </div>
+        <div class="stat-card">
+            <div class="stat-value">{{.Words}}</div>
+            <div class="stat-label">words written</div>
+        </div>
+        <div class="stat-card">
+            <div class="stat-value">{{.Current.Days}}</div>
+            <div class="stat-label">day current streak</div>
+            {{if .Current.Days}}<div class="stat-detail">since {{.Current.Start.Format "Jan 2, 2006"}}</div>{{end}}
+        </div>
+        <div class="stat-card">
+            <div class="stat-value">{{.Longest.Days}}</div>
+            <div class="stat-label">day longest streak</div>
+            {{if .Longest.Days}}<div class="stat-detail">{{.Longest.Start.Format "Jan 2, 2006"}} – {{.Longest.End.Format "Jan 2, 2006"}}</div>{{end}}
+        </div>
+    </div>
+
+    {{if .Dated}}
+    <div class="note">
+        <h2>Notes per year</h2>
+        {{.YearChart}}
+    </div>
+    <div class="note">
+        <h2>Notes per month</h2>
+        {{.MonthChart}}
+    </div>
+    <div class="note">
+        <h2>Notes per weekday</h2>
+        {{.WeekdayChart}}
+    </div>
+    <div class="note">
+        <h2>Busiest days</h2>
+        <ol class="stat-list">
+            {{range .Busiest}}
+            <li>{{.Date.Format "Monday, January 2, 2006"}} — {{.Notes}} {{if eq .Notes 1}}note{{else}}notes{{end}}, {{.Words}} words</li>
+            {{end}}
+        </ol>
+    </div>
+    {{end}}
+
+    {{if .Undated}}
+    <div class="note">
+        <h2>Notes without dates</h2>
+        <ul class="stat-list">
+            {{range .Undated}}<li>{{.}}</li>{{end}}
+        </ul>
+    </div>
+    {{end}}
+
+    <div class="footer">
+        Generated by Salthaven • <a href="/">Day</a> • <a href="/tasks">Tasks</a> • <a href="/review">Year in Review</a> • <a href="javascript:location.reload()">Refresh</a>
+    </div>
+
+    <script>
+{{template "theme-script"}}
+    </script>
+</body>
+</html>`
+
+// handleStats renders statistics for the whole vault
+func handleStats(folderPath string, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		s := stats.Compute(folderPath, v.notes, time.Now())
+		renderPage(w, "stats", StatsPageData{
+			Stats:        s,
+			YearChart:    barChart(s.ByYear),
+			MonthChart:   barChart(s.ByMonth[:]),
+			WeekdayChart: barChart(s.ByWeekday[:]),
+		})
+	}
+}
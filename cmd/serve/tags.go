@@ -0,0 +1,147 @@
+package serve
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Query parameters holding the tag filter
+const (
+	tagParam        = "tag"
+	excludeTagParam = "exclude-tag"
+)
+
+// TagChip represents a tag filter toggle on the page
+type TagChip struct {
+	Name       string
+	Count      int
+	Included   bool
+	Excluded   bool
+	IncludeURL string // Link that toggles including the tag
+	ExcludeURL string // Link that toggles excluding the tag
+}
+
+// queryList reads a repeatable, comma-separated query parameter
+func queryList(query url.Values, key string) []string {
+	var items []string
+	for _, value := range query[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = markdown.NormalizeTag(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// tagFilterFromQuery reads the tag filter from the tag and exclude-tag query parameters
+func tagFilterFromQuery(query url.Values) markdown.TagFilter {
+	return markdown.TagFilter{
+		Include: queryList(query, tagParam),
+		Exclude: queryList(query, excludeTagParam),
+	}
+}
+
+// toggled returns list with tag removed if present, or added if not
+func toggled(list []string, tag string) []string {
+	var result []string
+	found := false
+	for _, t := range list {
+		if t == tag {
+			found = true
+			continue
+		}
+		result = append(result, t)
+	}
+	if !found {
+		result = append(result, tag)
+	}
+	return result
+}
+
+// without returns list with tag removed
+func without(list []string, tag string) []string {
+	var result []string
+	for _, t := range list {
+		if t != tag {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// filterURL builds the page URL for a tag filter, keeping the other query parameters
+func filterURL(query url.Values, filter markdown.TagFilter) string {
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Del(tagParam)
+	values.Del(excludeTagParam)
+	for _, tag := range filter.Include {
+		values.Add(tagParam, tag)
+	}
+	for _, tag := range filter.Exclude {
+		values.Add(excludeTagParam, tag)
+	}
+
+	if len(values) == 0 {
+		return "?"
+	}
+	return "?" + values.Encode()
+}
+
+// tagChips builds a chip for each tag on the notes plus any tag already in the filter
+func tagChips(query url.Values, notes []markdown.Match, filter markdown.TagFilter) []TagChip {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		for _, tag := range note.Tags {
+			counts[tag]++
+		}
+	}
+	for _, tag := range filter.Include {
+		if _, ok := counts[tag]; !ok {
+			counts[tag] = 0
+		}
+	}
+	for _, tag := range filter.Exclude {
+		if _, ok := counts[tag]; !ok {
+			counts[tag] = 0
+		}
+	}
+
+	var chips []TagChip
+	for tag, count := range counts {
+		include := markdown.TagFilter{Include: toggled(filter.Include, tag), Exclude: without(filter.Exclude, tag)}
+		exclude := markdown.TagFilter{Include: without(filter.Include, tag), Exclude: toggled(filter.Exclude, tag)}
+		chips = append(chips, TagChip{
+			Name:       tag,
+			Count:      count,
+			Included:   contains(filter.Include, tag),
+			Excluded:   contains(filter.Exclude, tag),
+			IncludeURL: filterURL(query, include),
+			ExcludeURL: filterURL(query, exclude),
+		})
+	}
+
+	sort.Slice(chips, func(i, j int) bool {
+		if chips[i].Count != chips[j].Count {
+			return chips[i].Count > chips[j].Count
+		}
+		return chips[i].Name < chips[j].Name
+	})
+	return chips
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
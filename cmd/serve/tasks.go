@@ -0,0 +1,166 @@
+package serve
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// TaskEntry represents a task on the tasks page
+type TaskEntry struct {
+	markdown.Task
+	NotePath string // Vault-relative path of the note
+	Overdue  bool
+}
+
+// TasksPageData represents the data passed to the tasks template
+type TasksPageData struct {
+	Tasks   []TaskEntry
+	Filter  string
+	Filters []string
+	Count   int
+}
+
+const tasksTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Tasks - {{.Filter}}</title>
+    <style>
+{{template "style"}}
+        .filters {
+            text-align: center;
+            margin-bottom: 20px;
+        }
+        .filter {
+            display: inline-block;
+            margin: 0 4px;
+            padding: 4px 12px;
+            border-radius: 12px;
+            color: var(--text-secondary);
+            text-decoration: none;
+            border: 1px solid var(--border-color);
+        }
+        .filter.active {
+            color: var(--bg-secondary);
+            background: var(--text-accent);
+            border-color: var(--text-accent);
+        }
+        .task {
+            padding: 10px 0;
+            border-bottom: 1px solid var(--border-color);
+        }
+        .task:last-child {
+            border-bottom: none;
+        }
+        .task-meta {
+            color: var(--text-tertiary);
+            font-size: 0.8em;
+            margin-left: 18px;
+        }
+        .task-meta a {
+            color: var(--text-tertiary);
+        }
+        .overdue {
+            color: #e74c3c;
+        }
+    </style>
+</head>
+<body>
+    <div class="header">
+{{template "theme-toggle"}}
+        <h1>Tasks</h1>
+        <p>{{.Count}} {{.Filter}} {{if eq .Count 1}}task{{else}}tasks{{end}} • <a href="/" class="content-link">On This Day</a></p>
+    </div>
+
+    <div class="filters">
+        {{$current := .Filter}}
+        {{range .Filters}}
+        <a href="/tasks?status={{.}}" class="filter{{if eq . $current}} active{{end}}">{{.}}</a>
+        {{end}}
+    </div>
+
+    {{if .Tasks}}
+    <div class="note">
+        {{range .Tasks}}
+        <div class="task">
+            <div class="checkbox-item{{if .Done}} checked{{end}}">
+                <div class="checkbox{{if .Done}} checked{{end}}"></div>
+                <span class="checkbox-text">{{if .Cancelled}}<s>{{.Text}}</s>{{else}}{{.Text}}{{end}}</span>
+            </div>
+            <div class="task-meta">
+                <a href="/note?path={{.NotePath}}">{{.NoteDate.Format "January 2, 2006"}}</a> • {{.NotePath}}
+                {{if not .Due.IsZero}} • <span{{if .Overdue}} class="overdue"{{end}}>due {{.Due.Format "2006-01-02"}}</span>{{end}}
+                {{if not .DoneDate.IsZero}} • done {{.DoneDate.Format "2006-01-02"}}{{end}}
+                {{if .Priority}} • {{.Priority}} priority{{end}}
+            </div>
+        </div>
+        {{end}}
+    </div>
+    {{else}}
+        <div class="no-notes">
+            No {{.Filter}} tasks found
+        </div>
+    {{end}}
+
+    <div class="footer">
+        Generated by Salthaven • <a href="javascript:location.reload()">Refresh</a>
+    </div>
+
+    <script>
+{{template "theme-script"}}
+    </script>
+</body>
+</html>`
+
+// handleTasks renders the tasks of every dated note, filtered by the status query parameter
+func handleTasks(folderPath string, cache *vaultCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("status")
+		if filter == "" {
+			filter = markdown.TaskFilterOpen
+		}
+
+		v, err := cache.scan()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning folder: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		today := time.Now()
+		tasks, err := markdown.FilterTasks(markdown.CollectTasks(v.dated), filter, today)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var entries []TaskEntry
+		for _, task := range tasks {
+			relPath, err := filepath.Rel(folderPath, task.Path)
+			if err != nil {
+				relPath = task.Path
+			}
+			entries = append(entries, TaskEntry{
+				Task:     task,
+				NotePath: relPath,
+				Overdue:  task.Overdue(today),
+			})
+		}
+
+		renderPage(w, "tasks", TasksPageData{
+			Tasks:  entries,
+			Filter: filter,
+			Filters: []string{
+				markdown.TaskFilterOpen,
+				markdown.TaskFilterOverdue,
+				markdown.TaskFilterDone,
+				markdown.TaskFilterAll,
+			},
+			Count: len(entries),
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// barWidth is the width of the longest bar in a chart, in characters
+const barWidth = 30
+
+// bar draws a horizontal bar for value out of largest, using eighth blocks for the remainder
+func bar(value, largest int) string {
+	if value <= 0 || largest <= 0 {
+		return ""
+	}
+	eighths := value * barWidth * 8 / largest
+	if eighths == 0 {
+		eighths = 1
+	}
+	partial := []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	return strings.Repeat("█", eighths/8) + partial[eighths%8]
+}
+
+// plural formats a count with its noun, such as "1 note" or "3 notes"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// printChart prints a labelled bar chart of note counts
+func printChart(title string, counts []stats.Count) {
+	fmt.Printf("\n%s\n", title)
+	largest := stats.Max(counts)
+	for _, count := range counts {
+		fmt.Printf("  %-10s %-*s %d\n", count.Label, barWidth, bar(count.Notes, largest), count.Notes)
+	}
+}
+
+// Execute runs the stats command, summarising the vault's journaling
+func Execute(folderPath string, verbose bool, dateProperties []string) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanNotes(folderPath, dateProperties, verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+	// Check for results
+	if len(notes) == 0 {
+		return fmt.Errorf("no notes found")
+	}
+
+	s := stats.Compute(folderPath, notes, time.Now())
+	fmt.Printf("Notes:           %d (%d dated, %d undated)\n", s.Notes, s.Dated, len(s.Undated))
+	fmt.Printf("Words written:   %d\n", s.Words)
+	fmt.Printf("Current streak:  %s\n", s.Current)
+	fmt.Printf("Longest streak:  %s\n", s.Longest)
+
+	if s.Dated > 0 {
+		printChart("Notes per year", s.ByYear)
+		printChart("Notes per month", s.ByMonth[:])
+		printChart("Notes per weekday", s.ByWeekday[:])
+
+		fmt.Printf("\nBusiest days\n")
+		for _, day := range s.Busiest {
+			fmt.Printf("  %s  %s, %d words\n", day.Date.Format("2006-01-02"), plural(day.Notes, "note"), day.Words)
+		}
+	}
+
+	if len(s.Undated) > 0 {
+		fmt.Printf("\nNotes without dates\n")
+		for _, path := range s.Undated {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	return nil
+}
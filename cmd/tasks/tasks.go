@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// FormatTask renders a task as a single line of terminal output
+func FormatTask(task markdown.Task, folderPath string) string {
+	box := "[ ]"
+	if !task.Open() {
+		box = "[" + string(task.Status) + "]"
+	}
+
+	var details []string
+	if !task.Due.IsZero() {
+		details = append(details, "due "+task.Due.Format("2006-01-02"))
+	}
+	if !task.DoneDate.IsZero() {
+		details = append(details, "done "+task.DoneDate.Format("2006-01-02"))
+	}
+	if task.Priority != markdown.PriorityNone {
+		details = append(details, task.Priority+" priority")
+	}
+
+	relPath, err := filepath.Rel(folderPath, task.Path)
+	if err != nil {
+		relPath = task.Path
+	}
+
+	line := fmt.Sprintf("%s %s %s", task.NoteDate.Format("2006-01-02"), box, task.Text)
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%s  %s:%d", line, relPath, task.Line+1)
+}
+
+// Execute runs the tasks command, listing tasks from dated notes that match filter
+func Execute(folderPath string, verbose bool, filter string, dateProperties []string) error {
+	// Check if folder exists
+	if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+		return fmt.Errorf("folder does not exist: %s", folderPath)
+	}
+
+	notes, err := markdown.ScanDatedNotes(folderPath, dateProperties, verbose)
+	if err != nil {
+		return fmt.Errorf("error scanning folder: %v", err)
+	}
+
+	tasks, err := markdown.FilterTasks(markdown.CollectTasks(notes), filter, time.Now())
+	if err != nil {
+		return err
+	}
+	// Check for results
+	if len(tasks) == 0 {
+		return fmt.Errorf("no tasks found")
+	}
+	// Display results
+	for _, task := range tasks {
+		fmt.Printf("%s\n", FormatTask(task, folderPath))
+	}
+
+	return nil
+}
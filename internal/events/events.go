@@ -0,0 +1,115 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Event kinds
+const (
+	KindBirthday    = "birthday"
+	KindAnniversary = "anniversary"
+	KindMemorial    = "memorial"
+	KindOther       = "event"
+)
+
+// Event is a recurring date such as a birthday, anniversary or memorial
+type Event struct {
+	Name   string
+	Kind   string
+	Date   time.Time // When it first happened; recurs on the same day each year
+	Source string    // File the event was read from
+}
+
+// normalizeKind maps the kinds accepted in events files to the known kinds
+func normalizeKind(kind string) string {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "birthday", "born", "birth":
+		return KindBirthday
+	case "anniversary", "wedding":
+		return KindAnniversary
+	case "memorial", "died", "death", "passed":
+		return KindMemorial
+	}
+	return KindOther
+}
+
+// Occurrence is an event recurring on a reference date
+type Occurrence struct {
+	Event
+	Years int // Years since the event first happened
+}
+
+// Icon returns an emoji for the event kind
+func (o Occurrence) Icon() string {
+	switch o.Kind {
+	case KindBirthday:
+		return "🎂"
+	case KindAnniversary:
+		return "💍"
+	case KindMemorial:
+		return "🕯️"
+	}
+	return "📅"
+}
+
+// Title describes the event, such as "Alice's birthday"
+func (o Occurrence) Title() string {
+	switch o.Kind {
+	case KindBirthday:
+		if strings.HasSuffix(o.Name, "s") {
+			return o.Name + "' birthday"
+		}
+		return o.Name + "'s birthday"
+	case KindAnniversary:
+		if strings.Contains(strings.ToLower(o.Name), "anniversary") {
+			return o.Name
+		}
+		return o.Name + " anniversary"
+	case KindMemorial:
+		return "Remembering " + o.Name
+	}
+	return o.Name
+}
+
+// YearsLabel describes how long ago the event first happened, such as "34 years"
+func (o Occurrence) YearsLabel() string {
+	switch o.Years {
+	case 0:
+		return "this year"
+	case 1:
+		return "1 year"
+	}
+	return fmt.Sprintf("%d years", o.Years)
+}
+
+// String renders the occurrence as a single line, such as "🎂 Alice's birthday — 34 years"
+func (o Occurrence) String() string {
+	return fmt.Sprintf("%s %s — %s", o.Icon(), o.Title(), o.YearsLabel())
+}
+
+// Select returns the events that recur on the reference date according to
+// matcher, oldest first. Events are matched on their date just like notes.
+func Select(events []Event, matcher markdown.DateMatcher, referenceDate time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, event := range events {
+		if event.Date.Year() > referenceDate.Year() {
+			continue // Has not happened yet
+		}
+		if matcher(event.Date, referenceDate) {
+			occurrences = append(occurrences, Occurrence{
+				Event: event,
+				Years: referenceDate.Year() - event.Date.Year(),
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.Before(occurrences[j].Date)
+	})
+	return occurrences
+}
@@ -0,0 +1,209 @@
+package events
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// personProperties lists the person note properties holding dates and the kind
+// of event each records, in the order their events are listed. Properties of
+// the same kind are alternatives: the first holding a date is used.
+var personProperties = []struct {
+	Property string
+	Kind     string
+}{
+	{"birthday", KindBirthday},
+	{"anniversary", KindAnniversary},
+	{"died", KindMemorial},
+	{"death", KindMemorial},
+}
+
+// resolvePath makes a vault-relative path absolute
+func resolvePath(vaultPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(vaultPath, filepath.FromSlash(path))
+}
+
+// Load reads the events file and the person notes folder, either of which may be
+// empty. Relative paths are resolved against the vault.
+func Load(vaultPath, eventsFile, peopleFolder string) ([]Event, error) {
+	var events []Event
+
+	if eventsFile != "" {
+		fileEvents, err := LoadFile(resolvePath(vaultPath, eventsFile))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+
+	if peopleFolder != "" {
+		people, err := LoadPeople(resolvePath(vaultPath, peopleFolder))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, people...)
+	}
+
+	return events, nil
+}
+
+// LoadFile reads events from a YAML (.yaml, .yml) or CSV (.csv) file
+func LoadFile(path string) ([]Event, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read events file: %v", err)
+	}
+
+	var records []map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		records = parseYAMLList(string(content))
+	case ".csv":
+		records, err = parseCSV(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid events file %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported events file %s (want .yaml, .yml or .csv)", path)
+	}
+
+	var events []Event
+	for i, record := range records {
+		if record["name"] == "" || record["date"] == "" {
+			return nil, fmt.Errorf("invalid events file %s: event %d needs a name and a date", path, i+1)
+		}
+		date, err := markdown.ParseDate(record["date"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid events file %s: %s: %v", path, record["name"], err)
+		}
+		events = append(events, Event{
+			Name:   record["name"],
+			Kind:   normalizeKind(record["kind"]),
+			Date:   date,
+			Source: path,
+		})
+	}
+	return events, nil
+}
+
+// parseYAMLList parses a YAML list of flat mappings, optionally under a
+// top-level events key:
+//
+//   - name: Alice
+//     kind: birthday
+//     date: 1990-10-18
+func parseYAMLList(content string) []map[string]string {
+	var records []map[string]string
+	var current map[string]string
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			current = make(map[string]string)
+			records = append(records, current)
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			if trimmed == "" {
+				continue
+			}
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok || current == nil {
+			continue // Top-level keys such as events: only wrap the list
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return records
+}
+
+// parseCSV parses CSV with a header row naming the name, date and optional kind columns
+func parseCSV(content string) ([]map[string]string, error) {
+	rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var records []map[string]string
+	for _, row := range rows[1:] {
+		record := make(map[string]string)
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// LoadPeople reads events from the birthday, anniversary and died (or death) properties
+// of the notes in a folder of person notes
+func LoadPeople(folderPath string) ([]Event, error) {
+	var events []Event
+
+	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+			return nil
+		}
+
+		content, err := markdown.ReadFileContent(path)
+		if err != nil {
+			return nil // Continue processing other files
+		}
+
+		properties := markdown.ParseFrontmatter(content)
+		name := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+		if titles := properties["title"]; len(titles) > 0 && titles[0] != "" {
+			name = titles[0]
+		}
+
+		found := make(map[string]bool) // Kinds already read, so died and death give one memorial
+		for _, person := range personProperties {
+			values := properties[person.Property]
+			if len(values) == 0 || found[person.Kind] {
+				continue
+			}
+			date, err := markdown.ParseDate(values[0])
+			if err != nil {
+				continue
+			}
+			found[person.Kind] = true
+			events = append(events, Event{Name: name, Kind: person.Kind, Date: date, Source: path})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read people folder: %v", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPeople(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // Kind and date of each event
+	}{
+		{"Ana", "---\nbirthday: 1950-04-02\n---\n", []string{"birthday 1950-04-02"}},
+		{"Ben", "---\nbirthday: 1948-06-10\nanniversary: 1972-09-01\ndied: 2019-11-03\n---\n", []string{"birthday 1948-06-10", "anniversary 1972-09-01", "memorial 2019-11-03"}},
+		{"Cy", "---\ndied: 2020-01-05\ndeath: 2020-01-05\n---\n", []string{"memorial 2020-01-05"}},
+		{"Di", "---\ndied: 2021-02-03\ndeath: 2021-02-04\n---\n", []string{"memorial 2021-02-03"}},
+		{"Ed", "---\ndied: unknown\ndeath: 2022-03-04\n---\n", []string{"memorial 2022-03-04"}},
+		{"Flo", "No frontmatter\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			if err := os.WriteFile(filepath.Join(folder, tt.name+".md"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			events, err := LoadPeople(folder)
+			if err != nil {
+				t.Fatalf("LoadPeople() error = %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				if event.Name != tt.name {
+					t.Errorf("Name = %q, want %q", event.Name, tt.name)
+				}
+				got = append(got, event.Kind+" "+event.Date.Format("2006-01-02"))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadPeople() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("LoadPeople() = %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
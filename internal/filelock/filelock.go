@@ -0,0 +1,31 @@
+// Package filelock serializes writes to a vault file between the commands,
+// the server and other processes by locking a sidecar path.lock file.
+package filelock
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeout bounds how long a writer waits for another to finish
+const timeout = 5 * time.Second
+
+// pollInterval is how often a waiting writer retries the lock
+const pollInterval = 20 * time.Millisecond
+
+// Lock takes an exclusive lock on path, waiting up to a few seconds for other
+// writers. The returned function releases it.
+func Lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+	for {
+		unlock, err := tryLock(lockPath)
+		if err != nil || unlock != nil {
+			return unlock, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock on %s", path)
+		}
+		time.Sleep(pollInterval)
+	}
+}
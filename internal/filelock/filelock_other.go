@@ -0,0 +1,24 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"fmt"
+	"os"
+)
+
+// tryLock creates the lock file, returning nil while another process holds
+// it. Without flock a lock left by a crashed process has to be removed by hand,
+// as deleting it here could break a lock another waiter has just taken.
+func tryLock(lockPath string) (func(), error) {
+	file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fmt.Fprintf(file, "%d\n", os.Getpid())
+	file.Close()
+	return func() { os.Remove(lockPath) }, nil
+}
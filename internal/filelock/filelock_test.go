@@ -0,0 +1,57 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockExcludesOtherWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+
+	// Each writer checks that it is alone between locking and unlocking
+	const writers = 20
+	var mu sync.Mutex
+	holders, most := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := Lock(path)
+			if err != nil {
+				t.Errorf("Lock() error = %v", err)
+				return
+			}
+			mu.Lock()
+			holders++
+			most = max(most, holders)
+			mu.Unlock()
+
+			// Read, wait and write back, which loses writes unless the lock holds
+			data, _ := os.ReadFile(path)
+			time.Sleep(time.Millisecond)
+			if err := os.WriteFile(path, append(data, 'x'), 0644); err != nil {
+				t.Errorf("WriteFile() error = %v", err)
+			}
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if most != 1 {
+		t.Errorf("%d writers held the lock at once, want 1", most)
+	}
+	if data, _ := os.ReadFile(path); len(data) != writers {
+		t.Errorf("file has %d writes, want %d", len(data), writers)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package filelock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock takes an flock on the lock file, returning nil when another process
+// holds it. The kernel drops the lock if its holder dies, so a lock file left
+// behind is never mistaken for a held lock.
+func tryLock(lockPath string) (func(), error) {
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The holder before us removes the file on unlock, so the one we locked may
+	// no longer be the lock file at this path; if so, start over on the new one
+	held, err := file.Stat()
+	current, statErr := os.Stat(lockPath)
+	if err != nil || statErr != nil || !os.SameFile(held, current) {
+		file.Close()
+		return nil, nil
+	}
+
+	return func() {
+		// Remove the file while still holding the lock, so no one can lock it after us
+		os.Remove(lockPath)
+		file.Close()
+	}, nil
+}
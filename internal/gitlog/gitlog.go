@@ -0,0 +1,210 @@
+package gitlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// fieldSeparator and recordSeparator split git log's output, as neither
+// appears in commit subjects
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// logFormat prints each commit's hash, author name, author email, author date and subject
+const logFormat = "%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1e"
+
+// Commit is a commit from a repository's history
+type Commit struct {
+	Repo    string // Name of the repository's folder
+	Hash    string
+	Author  string
+	Email   string
+	Date    time.Time // Author date, in the author's time zone
+	Subject string
+}
+
+// ShortHash returns the abbreviated commit hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Occurrence is a commit authored on a day matching the reference date
+type Occurrence struct {
+	Commit
+	Years int // Years since the commit
+}
+
+// Icon returns an emoji marking commits
+func (o Occurrence) Icon() string {
+	return "💻"
+}
+
+// YearsLabel describes how long ago the commit was, such as "3 years ago"
+func (o Occurrence) YearsLabel() string {
+	if o.Years == 1 {
+		return "1 year ago"
+	}
+	return fmt.Sprintf("%d years ago", o.Years)
+}
+
+// String renders the commit as a single line, such as
+// "💻 salthaven: Fix leap day matching (1a2b3c4) — October 18, 2019 3:04 PM (7 years ago)"
+func (o Occurrence) String() string {
+	return fmt.Sprintf("%s %s: %s (%s) — %s (%s)", o.Icon(), o.Repo, o.Subject, o.ShortHash(),
+		o.Date.Format("January 2, 2006 3:04 PM"), o.YearsLabel())
+}
+
+// git runs a git command in the repository and returns its output. Messages
+// are kept in English, whatever the user's locale.
+func git(repoPath string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// parseLog reads the commits printed by git log in logFormat
+func parseLog(repo string, out []byte) ([]Commit, error) {
+	var commits []Commit
+	for _, record := range strings.Split(string(out), recordSeparator) {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSeparator, 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected git log output: %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit date %q: %v", fields[3], err)
+		}
+		commits = append(commits, Commit{
+			Repo:    repo,
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    date,
+			Subject: fields[4],
+		})
+	}
+	return commits, nil
+}
+
+// Load reads the commits of a local repository on any branch, merges left out.
+// Only the author's commits are read, matched by name or email as git log
+// --author does; an empty author means the repository's configured user.email,
+// or everyone when none is configured.
+func Load(repoPath, author string) ([]Commit, error) {
+	top, err := git(repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %s (%v)", repoPath, err)
+	}
+	repo := filepath.Base(strings.TrimSpace(string(top)))
+
+	if author == "" {
+		// git config exits with an error when the setting is missing
+		if email, err := git(repoPath, "config", "user.email"); err == nil {
+			author = strings.TrimSpace(string(email))
+		}
+	}
+
+	// A repository without commits has no history to show, and older versions
+	// of git log fail on it
+	refs, err := git(repoPath, "rev-parse", "--all")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(refs)) == "" {
+		return nil, nil
+	}
+
+	args := []string{"log", "--all", "--no-merges", "--format=" + logFormat}
+	if author != "" {
+		args = append(args, "--fixed-strings", "--author="+author)
+	}
+	out, err := git(repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(repo, out)
+}
+
+// resolveRepoPath resolves a repository path relative to the vault
+func resolveRepoPath(vaultPath, repoPath string) string {
+	if filepath.IsAbs(repoPath) {
+		return repoPath
+	}
+	return filepath.Join(vaultPath, filepath.FromSlash(repoPath))
+}
+
+// LoadAll reads the commits of each repository, relative paths being
+// resolved against the vault
+func LoadAll(vaultPath string, repoPaths []string, author string) ([]Commit, error) {
+	var commits []Commit
+	for _, repoPath := range repoPaths {
+		repoCommits, err := Load(resolveRepoPath(vaultPath, repoPath), author)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, repoCommits...)
+	}
+	return commits, nil
+}
+
+// Refs returns the commits every ref of each repository points at, relative
+// paths being resolved against the vault. It changes whenever a commit is
+// made, fetched or rewritten, so commits need only be loaded again then.
+func Refs(vaultPath string, repoPaths []string) (string, error) {
+	var refs strings.Builder
+	for _, repoPath := range repoPaths {
+		out, err := git(resolveRepoPath(vaultPath, repoPath), "rev-parse", "--all")
+		if err != nil {
+			return "", err
+		}
+		refs.WriteString(repoPath + "\n")
+		refs.Write(out)
+	}
+	return refs.String(), nil
+}
+
+// Select returns the commits authored in earlier years on a day matching the
+// reference date, newest first. Commits are matched on their author date in
+// the author's time zone, the day it was where they committed.
+func Select(commits []Commit, matcher markdown.DateMatcher, referenceDate time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, commit := range commits {
+		if commit.Date.Year() < referenceDate.Year() && matcher(commit.Date, referenceDate) {
+			occurrences = append(occurrences, Occurrence{
+				Commit: commit,
+				Years:  referenceDate.Year() - commit.Date.Year(),
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date.After(occurrences[j].Date)
+	})
+	return occurrences
+}
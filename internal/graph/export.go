@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats
+const (
+	FormatDOT     = "dot"
+	FormatGraphML = "graphml"
+	FormatJSON    = "json"
+)
+
+// Write exports the graph in a format: dot for Graphviz, graphml for Gephi
+// and yEd, or json with nodes and edges lists for D3 and similar tools
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatGraphML:
+		return g.WriteGraphML(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown graph format %q (want dot, graphml or json)", format)
+}
+
+// dateLabel formats a node's date, or "" when it has none
+func (n Node) dateLabel() string {
+	if n.Date.IsZero() {
+		return ""
+	}
+	return n.Date.Format("2006-01-02")
+}
+
+// WriteDOT exports the graph in Graphviz DOT. Missing notes are drawn dashed.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph salthaven {\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, node := range g.Nodes {
+		attrs := []string{"label=" + strconv.Quote(node.Label)}
+		if date := node.dateLabel(); date != "" {
+			attrs = append(attrs, "tooltip="+strconv.Quote(date))
+		}
+		if node.Missing {
+			attrs = append(attrs, `style="rounded,dashed"`)
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", strconv.Quote(node.ID), strings.Join(attrs, ", "))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", strconv.Quote(edge.From), strconv.Quote(edge.To))
+		if edge.Count > 1 {
+			fmt.Fprintf(&b, " [weight=%d]", edge.Count)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graphML mirrors the GraphML document structure for encoding/xml
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML exports the graph in GraphML, with label, path, date, tags and missing node attributes
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "path", For: "node", AttrName: "path", AttrType: "string"},
+			{ID: "date", For: "node", AttrName: "date", AttrType: "string"},
+			{ID: "tags", For: "node", AttrName: "tags", AttrType: "string"},
+			{ID: "missing", For: "node", AttrName: "missing", AttrType: "boolean"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+		},
+		Graph: graphMLGraph{ID: "salthaven", EdgeDefault: "directed"},
+	}
+
+	for _, node := range g.Nodes {
+		data := []graphMLData{{Key: "label", Value: node.Label}}
+		if node.Path != "" {
+			data = append(data, graphMLData{Key: "path", Value: node.Path})
+		}
+		if date := node.dateLabel(); date != "" {
+			data = append(data, graphMLData{Key: "date", Value: date})
+		}
+		if len(node.Tags) > 0 {
+			data = append(data, graphMLData{Key: "tags", Value: strings.Join(node.Tags, ",")})
+		}
+		data = append(data, graphMLData{Key: "missing", Value: strconv.FormatBool(node.Missing)})
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: node.ID, Data: data})
+	}
+	for _, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.From,
+			Target: edge.To,
+			Data:   []graphMLData{{Key: "weight", Value: strconv.Itoa(edge.Count)}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonNode and jsonEdge are the JSON export records
+type jsonNode struct {
+	ID      string   `json:"id"`
+	Label   string   `json:"label"`
+	Path    string   `json:"path,omitempty"`
+	Date    string   `json:"date,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Missing bool     `json:"missing,omitempty"`
+}
+
+type jsonEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Count  int    `json:"count"`
+}
+
+// WriteJSON exports the graph as {"nodes": [...], "edges": [...]}
+func (g *Graph) WriteJSON(w io.Writer) error {
+	doc := struct {
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{
+		Nodes: []jsonNode{},
+		Edges: []jsonEdge{},
+	}
+
+	for _, node := range g.Nodes {
+		doc.Nodes = append(doc.Nodes, jsonNode{
+			ID:      node.ID,
+			Label:   node.Label,
+			Path:    node.Path,
+			Date:    node.dateLabel(),
+			Tags:    node.Tags,
+			Missing: node.Missing,
+		})
+	}
+	for _, edge := range g.Edges {
+		doc.Edges = append(doc.Edges, jsonEdge{Source: edge.From, Target: edge.To, Count: edge.Count})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Node is a note in the link graph, or a link target with no note yet
+type Node struct {
+	ID      string    // Vault-relative path without .md, or the target of a missing note
+	Path    string    // Vault-relative path, empty when missing
+	Label   string    // File name without .md, as Obsidian shows it
+	Date    time.Time // Zero when the note is undated or missing
+	Tags    []string
+	Missing bool // Linked to but not created
+}
+
+// Edge is one or more links from one note to another
+type Edge struct {
+	From  string
+	To    string
+	Count int
+}
+
+// Graph is the vault's notes and the links between them
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+	nodes map[string]int // Node index by ID
+	// Names the notes can be linked by, lowercased: the path and the file name without .md
+	byPath map[string]string
+	byName map[string][]string
+}
+
+// Build extracts the links of every note and resolves them the way Obsidian
+// does: by vault-relative path, by path relative to the linking note, or by
+// file name alone. Links to attachments are left out.
+func Build(folderPath string, notes []markdown.Note) *Graph {
+	g := &Graph{
+		nodes:  make(map[string]int),
+		byPath: make(map[string]string),
+		byName: make(map[string][]string),
+	}
+
+	ids := make([]string, len(notes))
+	for i, note := range notes {
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+		relPath = filepath.ToSlash(relPath)
+		id := strings.TrimSuffix(relPath, path.Ext(relPath))
+		ids[i] = id
+
+		g.addNode(Node{
+			ID:    id,
+			Path:  relPath,
+			Label: path.Base(id),
+			Date:  note.Date,
+			Tags:  note.Tags,
+		})
+		g.byPath[strings.ToLower(id)] = id
+		name := strings.ToLower(path.Base(id))
+		g.byName[name] = append(g.byName[name], id)
+	}
+
+	// Prefer the note nearest the vault root when several share a name
+	for _, named := range g.byName {
+		sort.Slice(named, func(i, j int) bool {
+			if depth := strings.Count(named[i], "/") - strings.Count(named[j], "/"); depth != 0 {
+				return depth < 0
+			}
+			return named[i] < named[j]
+		})
+	}
+
+	edges := make(map[[2]string]int)
+	for i, note := range notes {
+		from := ids[i]
+		for _, link := range markdown.ExtractLinks(note.Content) {
+			to, ok := g.resolve(link.Target, path.Dir(from))
+			if !ok || to == from {
+				continue
+			}
+			key := [2]string{from, to}
+			if edges[key] == 0 {
+				g.Edges = append(g.Edges, Edge{From: from, To: to})
+			}
+			edges[key]++
+		}
+	}
+	for i := range g.Edges {
+		g.Edges[i].Count = edges[[2]string{g.Edges[i].From, g.Edges[i].To}]
+	}
+
+	return g
+}
+
+// addNode adds a node unless one with its ID exists
+func (g *Graph) addNode(node Node) {
+	if _, ok := g.nodes[node.ID]; ok {
+		return
+	}
+	g.nodes[node.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, node)
+}
+
+// resolve finds the note a link target refers to, adding a missing node for
+// a note that does not exist. It reports false for links to attachments.
+func (g *Graph) resolve(target, fromDir string) (string, bool) {
+	target = strings.TrimPrefix(filepath.ToSlash(target), "/")
+	ext := path.Ext(target)
+	if strings.EqualFold(ext, ".md") {
+		target = strings.TrimSuffix(target, ext)
+		ext = ""
+	}
+	key := strings.ToLower(target)
+
+	if id, ok := g.byPath[strings.ToLower(path.Join(fromDir, target))]; ok {
+		return id, true
+	}
+	if id, ok := g.byPath[key]; ok {
+		return id, true
+	}
+	if !strings.Contains(target, "/") {
+		if ids := g.byName[key]; len(ids) > 0 {
+			return ids[0], true
+		}
+	}
+
+	// Names with another extension are attachments such as photo.jpg
+	if ext != "" {
+		return "", false
+	}
+
+	// Obsidian treats an unresolved link as a note yet to be written
+	for _, node := range g.Nodes {
+		if node.Missing && strings.ToLower(node.ID) == key {
+			return node.ID, true
+		}
+	}
+	g.addNode(Node{ID: target, Label: path.Base(target), Missing: true})
+	return target, true
+}
+
+// Node returns the node with an ID
+func (g *Graph) Node(id string) (Node, bool) {
+	i, ok := g.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return g.Nodes[i], true
+}
+
+// Backlinks returns the notes linking to the note at a vault-relative path, newest first
+func (g *Graph) Backlinks(relPath string) []Node {
+	relPath = filepath.ToSlash(relPath)
+	id := strings.TrimSuffix(relPath, path.Ext(relPath))
+
+	var backlinks []Node
+	for _, edge := range g.Edges {
+		if edge.To == id {
+			if node, ok := g.Node(edge.From); ok {
+				backlinks = append(backlinks, node)
+			}
+		}
+	}
+
+	sort.SliceStable(backlinks, func(i, j int) bool {
+		return backlinks[i].Date.After(backlinks[j].Date)
+	})
+	return backlinks
+}
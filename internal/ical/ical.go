@@ -0,0 +1,127 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Date formats of iCalendar values (RFC 5545)
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// maxLineOctets is the longest content line allowed before folding
+const maxLineOctets = 75
+
+// Event is a calendar event, all-day or timed
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Categories  []string
+	Start       time.Time // First day of an all-day event, or the start time in local time
+	End         time.Time // Day after the last of an all-day event, or the end time
+	AllDay      bool
+	RRule       string      // Recurrence rule, such as FREQ=YEARLY, or empty
+	ExDates     []time.Time // Starts of the occurrences the rule leaves out
+	Source      string      // File the event was read from
+}
+
+// Calendar is a named list of events
+type Calendar struct {
+	Name   string
+	Events []Event
+	Stamp  time.Time // When the calendar was generated, for each event's DTSTAMP
+}
+
+// escapeText escapes a TEXT value: backslashes, semicolons, commas and newlines
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// foldLine splits a content line longer than 75 octets onto continuation lines
+// starting with a space, without splitting a UTF-8 character
+func foldLine(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // Leave room for the leading space
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// Write writes the calendar as an iCalendar file
+func (c Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		bw.WriteString(foldLine(name+":"+value) + "\r\n")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Salthaven//On This Day//EN")
+	line("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+
+	stamp := c.Stamp.UTC().Format(dateTimeFormat)
+	for _, event := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", stamp)
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE", event.Start.Format(dateFormat))
+			line("DTEND;VALUE=DATE", event.End.Format(dateFormat))
+		} else {
+			line("DTSTART", event.Start.UTC().Format(dateTimeFormat))
+			line("DTEND", event.End.UTC().Format(dateTimeFormat))
+		}
+		if event.RRule != "" {
+			line("RRULE", event.RRule)
+		}
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", escapeText(event.Location))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				categories[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(categories, ","))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
@@ -0,0 +1,106 @@
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// summaryLength is the most of a note's opening shown in an event's description
+const summaryLength = 300
+
+// NoteOptions configures how notes become events
+type NoteOptions struct {
+	// Repeat each note every year on its date, as an anniversary
+	Anniversaries bool
+	// When anniversaries of February 29 notes come up in non-leap years
+	LeapDay markdown.LeapDayPolicy
+}
+
+// ObsidianURL returns the obsidian:// link that opens a vault-relative note
+func ObsidianURL(folderPath, relPath string) string {
+	vault := folderPath
+	if abs, err := filepath.Abs(folderPath); err == nil {
+		vault = abs
+	}
+	file := strings.TrimSuffix(filepath.ToSlash(relPath), filepath.Ext(relPath))
+	return "obsidian://open?vault=" + url.QueryEscape(filepath.Base(vault)) + "&file=" + url.QueryEscape(file)
+}
+
+// noteUID derives an event UID from the note's path, so it stays the same
+// each time the calendar is exported
+func noteUID(relPath string, anniversary bool) string {
+	sum := sha1.Sum([]byte(filepath.ToSlash(relPath)))
+	kind := "note"
+	if anniversary {
+		kind = "anniversary"
+	}
+	return kind + "-" + hex.EncodeToString(sum[:8]) + "@salthaven"
+}
+
+// FromNotes turns each dated note into an all-day event covering its days
+func FromNotes(folderPath string, notes []markdown.Note, opts NoteOptions) []Event {
+	var events []Event
+	for _, note := range notes {
+		if !note.Dated() {
+			continue
+		}
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+
+		start := time.Date(note.Date.Year(), note.Date.Month(), note.Date.Day(), 0, 0, 0, 0, time.UTC)
+		end := time.Date(note.End.Year(), note.End.Month(), note.End.Day(), 0, 0, 0, 0, time.UTC)
+		if end.Before(start) {
+			end = start
+		}
+		event := Event{
+			UID:         noteUID(relPath, opts.Anniversaries),
+			Summary:     markdown.Title(note.Path, note.Content),
+			Description: markdown.Excerpt(note.Content, summaryLength),
+			URL:         ObsidianURL(folderPath, relPath),
+			Categories:  note.Tags,
+			Start:       start,
+			End:         end.AddDate(0, 0, 1),
+			AllDay:      true,
+		}
+
+		var standIns []Event // Extra events for leap day anniversaries
+		if opts.Anniversaries {
+			event.Summary = fmt.Sprintf("%s (%d)", event.Summary, start.Year())
+			event.RRule = "FREQ=YEARLY"
+			if start.Month() == time.February && start.Day() == 29 {
+				switch opts.LeapDay {
+				case markdown.LeapDayExact:
+					// A yearly rule skips the years without a February 29
+				case markdown.LeapDayMar1:
+					// Day 60 is February 29 in leap years and March 1 in others
+					event.RRule = "FREQ=YEARLY;BYYEARDAY=60"
+				case markdown.LeapDayBoth:
+					// One rule can't come up twice in other years, so March 1
+					// of non-leap years gets an event of its own
+					event.RRule = "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+					mar1 := event
+					mar1.UID = "mar1-" + event.UID
+					mar1.Start = time.Date(start.Year()+1, time.March, 1, 0, 0, 0, 0, time.UTC)
+					mar1.End = mar1.Start.Add(event.End.Sub(event.Start))
+					mar1.RRule = "FREQ=YEARLY;BYMONTH=3;BYYEARDAY=60"
+					standIns = append(standIns, mar1)
+				default:
+					// The last day of February, the 28th in non-leap years
+					event.RRule = "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+				}
+			}
+		}
+		events = append(events, event)
+		events = append(events, standIns...)
+	}
+	return events
+}
@@ -0,0 +1,235 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationRegex matches a DURATION value such as P1D, PT1H30M or P2W
+var durationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// property is one content line: NAME;PARAM=VALUE:value
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// unfoldLines joins continuation lines, which start with a space or tab, onto the line before
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+// Parameter values may be quoted, and quoted values may contain colons.
+func parseProperty(line string) (property, bool) {
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		Name:   strings.ToUpper(parts[0]),
+		Params: make(map[string]string),
+		Value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, true
+}
+
+// unescapeText reverses the escaping of a TEXT value
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
+
+// parseTime reads a DATE or DATE-TIME value. Dates are all-day and kept as
+// UTC midnight, like note dates; times with a Z are UTC, times with a TZID are
+// in that zone and others are floating, read in local time.
+func parseTime(prop property) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.Value)
+	if prop.Params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		return t.Local(), false, err
+	}
+
+	loc := time.Local
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t.Local(), false, err
+}
+
+// parseDuration reads a DURATION value
+func parseDuration(value string) (time.Duration, error) {
+	m := durationRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// Parse reads the VEVENTs of an iCalendar file. Events changing a single
+// occurrence of a recurring event (RECURRENCE-ID) replace that occurrence.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	var recurrenceID time.Time
+	var duration time.Duration
+	hasEnd := false
+	nested := 0 // Depth of components inside the event, such as VALARM
+	overrides := make(map[string][]time.Time)
+
+	for n, line := range lines {
+		prop, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			event = &Event{}
+			recurrenceID = time.Time{}
+			duration = 0
+			hasEnd = false
+			nested = 0
+			continue
+		case event == nil:
+			continue
+		case prop.Name == "BEGIN":
+			nested++
+			continue
+		case prop.Name == "END" && nested > 0:
+			nested--
+			continue
+		case nested > 0:
+			continue
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT"):
+			if event.Start.IsZero() {
+				event = nil // DTSTART is required
+				continue
+			}
+			if !hasEnd {
+				switch {
+				case duration != 0:
+					event.End = event.Start.Add(duration)
+				case event.AllDay:
+					event.End = event.Start.AddDate(0, 0, 1)
+				default:
+					event.End = event.Start
+				}
+			}
+			if !recurrenceID.IsZero() {
+				overrides[event.UID] = append(overrides[event.UID], recurrenceID)
+			}
+			events = append(events, *event)
+			event = nil
+			continue
+		}
+
+		var err error
+		switch prop.Name {
+		case "UID":
+			event.UID = prop.Value
+		case "SUMMARY":
+			event.Summary = unescapeText(prop.Value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(prop.Value)
+		case "LOCATION":
+			event.Location = unescapeText(prop.Value)
+		case "URL":
+			event.URL = prop.Value
+		case "CATEGORIES":
+			for _, category := range strings.Split(prop.Value, ",") {
+				if category = unescapeText(strings.TrimSpace(category)); category != "" {
+					event.Categories = append(event.Categories, category)
+				}
+			}
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseTime(prop)
+		case "DTEND":
+			event.End, _, err = parseTime(prop)
+			hasEnd = err == nil
+		case "DURATION":
+			duration, err = parseDuration(prop.Value)
+		case "RRULE":
+			event.RRule = prop.Value
+		case "EXDATE":
+			for _, value := range strings.Split(prop.Value, ",") {
+				var exdate time.Time
+				exdate, _, err = parseTime(property{Params: prop.Params, Value: value})
+				if err != nil {
+					break
+				}
+				event.ExDates = append(event.ExDates, exdate)
+			}
+		case "RECURRENCE-ID":
+			recurrenceID, _, err = parseTime(prop)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", n+1, prop.Name, err)
+		}
+	}
+
+	// The changed occurrences are events of their own, so leave them out of the rule
+	for i := range events {
+		if events[i].RRule != "" {
+			events[i].ExDates = append(events[i].ExDates, overrides[events[i].UID]...)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,174 @@
+package ical
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCalendar has a timed, an all-day and a recurring event, with one
+// occurrence moved, in the CRLF lines and folding a calendar app writes
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:call\r\n" +
+	"SUMMARY:Call with Ana\\, Ben\\; and Cy\\nabout the tri\r\n" +
+	" p\r\n" +
+	"DTSTART;TZID=America/New_York:20240310T090000\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"CATEGORIES:Work,Travel\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:Not the event's\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20240704\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"DTSTART:20240101T150000Z\r\n" +
+	"DTEND:20240101T151500Z\r\n" +
+	"RRULE:FREQ=DAILY;COUNT=5\r\n" +
+	"EXDATE:20240102T150000Z,20240103T150000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"RECURRENCE-ID:20240104T150000Z\r\n" +
+	"DTSTART:20240104T170000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:No start\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func utc(year int, month time.Month, d, hour, min int) time.Time {
+	return time.Date(year, month, d, hour, min, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	events, err := Parse(strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Parse() returned %d events, want 4", len(events))
+	}
+
+	tests := []struct {
+		event   Event
+		summary string
+		start   time.Time
+		end     time.Time
+		allDay  bool
+		exdates []time.Time
+	}{
+		{
+			// Daylight saving time starts that morning in New York
+			event:   events[0],
+			summary: "Call with Ana, Ben; and Cy\nabout the trip",
+			start:   utc(2024, time.March, 10, 13, 0),
+			end:     utc(2024, time.March, 10, 14, 30),
+		},
+		{
+			event:   events[1],
+			summary: "Holiday",
+			start:   utc(2024, time.July, 4, 0, 0),
+			end:     utc(2024, time.July, 5, 0, 0),
+			allDay:  true,
+		},
+		{
+			event:   events[2],
+			summary: "Standup",
+			start:   utc(2024, time.January, 1, 15, 0),
+			end:     utc(2024, time.January, 1, 15, 15),
+			exdates: []time.Time{utc(2024, time.January, 2, 15, 0), utc(2024, time.January, 3, 15, 0), utc(2024, time.January, 4, 15, 0)},
+		},
+		{
+			event:   events[3],
+			summary: "Standup (moved)",
+			start:   utc(2024, time.January, 4, 17, 0),
+			end:     utc(2024, time.January, 4, 17, 0),
+		},
+	}
+	for _, tt := range tests {
+		e := tt.event
+		if e.Summary != tt.summary {
+			t.Errorf("%s: Summary = %q, want %q", e.UID, e.Summary, tt.summary)
+		}
+		if !e.Start.Equal(tt.start) || !e.End.Equal(tt.end) || e.AllDay != tt.allDay {
+			t.Errorf("%s: Start, End, AllDay = %v, %v, %v; want %v, %v, %v", e.UID, e.Start, e.End, e.AllDay, tt.start, tt.end, tt.allDay)
+		}
+		if len(e.ExDates) != len(tt.exdates) {
+			t.Errorf("%s: ExDates = %v, want %v", e.UID, e.ExDates, tt.exdates)
+			continue
+		}
+		for i := range e.ExDates {
+			if !e.ExDates[i].Equal(tt.exdates[i]) {
+				t.Errorf("%s: ExDates = %v, want %v", e.UID, e.ExDates, tt.exdates)
+				break
+			}
+		}
+	}
+	if want := []string{"Work", "Travel"}; !reflect.DeepEqual(events[0].Categories, want) {
+		t.Errorf("Categories = %q, want %q", events[0].Categories, want)
+	}
+	if events[0].Description != "" {
+		t.Errorf("Description = %q, want the alarm's left out", events[0].Description)
+	}
+
+	// The moved occurrence is left out of the rule, and so are the excluded ones
+	if got := events[2].Starts(utc(2025, time.January, 1, 0, 0)); len(got) != 2 || !got[1].Equal(utc(2024, time.January, 5, 15, 0)) {
+		t.Errorf("Starts() = %v, want January 1 and 5", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"DTSTART:yesterday", "line 3: DTSTART"},
+		{"RECURRENCE-ID:later", "line 3: RECURRENCE-ID"},
+		{"DURATION:1 hour", "line 3: DURATION"},
+		{"EXDATE:20240101T090000Z,soon", "line 3: EXDATE"},
+	}
+	for _, tt := range tests {
+		calendar := "BEGIN:VCALENDAR\nBEGIN:VEVENT\n" + tt.line + "\nEND:VEVENT\nEND:VCALENDAR\n"
+		_, err := Parse(strings.NewReader(calendar))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want it to start with %q", tt.line, err, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"P1D", 24 * time.Hour, false},
+		{"P2W", 14 * 24 * time.Hour, false},
+		{"PT1H30M", 90 * time.Minute, false},
+		{"P1DT12H", 36 * time.Hour, false},
+		{"PT45S", 45 * time.Second, false},
+		{"-PT15M", -15 * time.Minute, false},
+		{"+PT15M", 15 * time.Minute, false},
+		{"1H", 0, true},
+		{"PT1.5H", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekdays maps the two-letter day names of BYDAY to weekdays
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// byDay is a BYDAY entry: a weekday, optionally the nth of the month or year
+// (negative counting from the end, zero for every one)
+type byDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// rule is a parsed recurrence rule. Only the parts journal and personal
+// calendars use are supported: FREQ, INTERVAL, COUNT, UNTIL, BYMONTH,
+// BYMONTHDAY and BYDAY.
+type rule struct {
+	Freq       string // DAILY, WEEKLY, MONTHLY or YEARLY
+	Interval   int
+	Count      int       // Zero for no limit
+	Until      time.Time // Zero for no limit
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []byDay
+}
+
+// parseInts reads a comma-separated list of integers within [-limit, limit], zero excluded
+func parseInts(value string, limit int) ([]int, error) {
+	var numbers []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n == 0 || n < -limit || n > limit {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// parseRule reads an RRULE value such as FREQ=MONTHLY;BYDAY=2SU
+func parseRule(value string) (rule, error) {
+	r := rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		val = strings.ToUpper(strings.TrimSpace(val))
+
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.Freq = val
+			default:
+				return rule{}, fmt.Errorf("unsupported frequency %s", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule{}, fmt.Errorf("invalid interval %q", val)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule{}, fmt.Errorf("invalid count %q", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, _, err := parseTime(property{Value: val})
+			if err != nil {
+				return rule{}, fmt.Errorf("invalid until %q", val)
+			}
+			r.Until = until
+		case "BYMONTH":
+			months, err := parseInts(val, 12)
+			if err != nil {
+				return rule{}, err
+			}
+			for _, month := range months {
+				if month < 0 {
+					return rule{}, fmt.Errorf("invalid month %d", month)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(month))
+			}
+		case "BYMONTHDAY":
+			days, err := parseInts(val, 31)
+			if err != nil {
+				return rule{}, err
+			}
+			r.ByMonthDay = days
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				day = strings.TrimSpace(day)
+				if len(day) < 2 {
+					return rule{}, fmt.Errorf("invalid day %q", day)
+				}
+				weekday, ok := weekdays[day[len(day)-2:]]
+				if !ok {
+					return rule{}, fmt.Errorf("invalid day %q", day)
+				}
+				entry := byDay{Weekday: weekday}
+				if ordinal := day[:len(day)-2]; ordinal != "" {
+					n, err := strconv.Atoi(ordinal)
+					if err != nil || n == 0 || n < -53 || n > 53 {
+						return rule{}, fmt.Errorf("invalid day %q", day)
+					}
+					entry.Ordinal = n
+				}
+				r.ByDay = append(r.ByDay, entry)
+			}
+		case "WKST":
+			// Weeks start on Monday; other week starts only shift WEEKLY rules with an interval
+		default:
+			return rule{}, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+	if r.Freq == "" {
+		return rule{}, fmt.Errorf("rule has no frequency")
+	}
+	return r, nil
+}
+
+// daysIn returns the number of days in the month of t
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// mondayOf returns the Monday starting the week of t
+func mondayOf(t time.Time) time.Time {
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// inPeriod reports whether day falls in a period the interval keeps, counting from start
+func (r rule) inPeriod(start, day time.Time) bool {
+	var periods int
+	switch r.Freq {
+	case "DAILY":
+		periods = int(day.Sub(start).Hours() / 24)
+	case "WEEKLY":
+		periods = int(mondayOf(day).Sub(mondayOf(start)).Hours() / (24 * 7))
+	case "MONTHLY":
+		periods = (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+	case "YEARLY":
+		periods = day.Year() - start.Year()
+	}
+	return periods%r.Interval == 0
+}
+
+// matchesDay reports whether day matches the rule's BYDAY entries. Ordinals
+// count within the month, or within the year for yearly rules without BYMONTH.
+func (r rule) matchesDay(day time.Time) bool {
+	inYear := r.Freq == "YEARLY" && len(r.ByMonth) == 0
+	for _, entry := range r.ByDay {
+		if day.Weekday() != entry.Weekday {
+			continue
+		}
+		if entry.Ordinal == 0 {
+			return true
+		}
+		var nth, fromEnd int
+		if inYear {
+			last := time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+			nth = (day.YearDay()-1)/7 + 1
+			fromEnd = -((last.YearDay()-day.YearDay())/7 + 1)
+		} else {
+			nth = (day.Day()-1)/7 + 1
+			fromEnd = -((daysIn(day)-day.Day())/7 + 1)
+		}
+		if entry.Ordinal == nth || entry.Ordinal == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the rule produces day, given the first occurrence's
+// day. Parts the rule leaves out default to the start's month, day or weekday.
+func (r rule) matches(start, day time.Time) bool {
+	if !r.inPeriod(start, day) {
+		return false
+	}
+
+	if len(r.ByMonth) > 0 {
+		found := false
+		for _, month := range r.ByMonth {
+			found = found || day.Month() == month
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		found := false
+		for _, n := range r.ByMonthDay {
+			found = found || day.Day() == n || day.Day() == daysIn(day)+n+1
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 && !r.matchesDay(day) {
+		return false
+	}
+
+	// Defaults taken from the start
+	switch r.Freq {
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+	case "MONTHLY":
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			return day.Day() == start.Day()
+		}
+	case "YEARLY":
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+				return false
+			}
+			return day.Day() == start.Day()
+		}
+	}
+	return true
+}
+
+// civilDay returns the calendar day of t, as UTC midnight
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// periodStart returns the first day of the FREQ period containing day
+func (r rule) periodStart(day time.Time) time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		return mondayOf(day)
+	case "MONTHLY":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "YEARLY":
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// nextPeriod returns the first day of the period INTERVAL periods after the one starting at period
+func (r rule) nextPeriod(period time.Time) time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		return period.AddDate(0, 0, 7*r.Interval)
+	case "MONTHLY":
+		return period.AddDate(0, r.Interval, 0)
+	case "YEARLY":
+		return period.AddDate(r.Interval, 0, 0)
+	}
+	return period.AddDate(0, 0, r.Interval)
+}
+
+// monthDays returns the days of a month the rule could produce, in order:
+// its BYMONTHDAY days, every day for BYDAY to pick from, or else the start's day
+func (r rule) monthDays(first time.Time, year int, month time.Month) []time.Time {
+	n := daysIn(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0:
+		seen := make(map[int]bool)
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = n + d + 1
+			}
+			if d >= 1 && d <= n && !seen[d] {
+				seen[d] = true
+				days = append(days, d)
+			}
+		}
+		sort.Ints(days)
+	case len(r.ByDay) > 0:
+		for d := 1; d <= n; d++ {
+			days = append(days, d)
+		}
+	case first.Day() <= n:
+		days = []int{first.Day()}
+	}
+
+	dates := make([]time.Time, len(days))
+	for i, d := range days {
+		dates[i] = time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	return dates
+}
+
+// candidates returns the days of the period starting at period that the rule
+// could produce, in order. matches still decides which it does.
+func (r rule) candidates(first, period time.Time) []time.Time {
+	switch r.Freq {
+	case "WEEKLY":
+		days := make([]time.Time, 7)
+		for i := range days {
+			days[i] = period.AddDate(0, 0, i)
+		}
+		return days
+	case "MONTHLY":
+		return r.monthDays(first, period.Year(), period.Month())
+	case "YEARLY":
+		// Yearly rules keep to the start's month unless they pick days in every month
+		months := []time.Month{first.Month()}
+		if len(r.ByMonth) > 0 {
+			months = append([]time.Month(nil), r.ByMonth...)
+			sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+		} else if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+			months = months[:0]
+			for month := time.January; month <= time.December; month++ {
+				months = append(months, month)
+			}
+		}
+		var days []time.Time
+		for i, month := range months {
+			if i > 0 && month == months[i-1] {
+				continue
+			}
+			days = append(days, r.monthDays(first, period.Year(), month)...)
+		}
+		return days
+	}
+	return []time.Time{period}
+}
+
+// withDay adds day to the ordered days, as the first start always counts
+// whether or not the rule produces it
+func withDay(days []time.Time, day time.Time) []time.Time {
+	i := sort.Search(len(days), func(i int) bool { return !days[i].Before(day) })
+	if i < len(days) && days[i].Equal(day) {
+		return days
+	}
+	return append(days[:i:i], append([]time.Time{day}, days[i:]...)...)
+}
+
+// Starts returns when the event starts before the given day: its first start,
+// then each time its recurrence rule repeats it, leaving out EXDATEs. An event
+// whose rule cannot be read only happens once.
+func (e Event) Starts(before time.Time) []time.Time {
+	first := civilDay(e.Start)
+	last := civilDay(before).AddDate(0, 0, -1)
+	if first.After(last) {
+		return nil
+	}
+	r, err := parseRule(e.RRule)
+	if e.RRule == "" || err != nil {
+		if e.excluded(e.Start) {
+			return nil
+		}
+		return []time.Time{e.Start}
+	}
+	if !r.Until.IsZero() && civilDay(r.Until).Before(last) {
+		last = civilDay(r.Until)
+	}
+
+	// Step through the periods the interval keeps, checking only the days in
+	// each the rule's parts could produce
+	var starts []time.Time
+	count := 0
+periods:
+	for period := r.periodStart(first); !period.After(last); period = r.nextPeriod(period) {
+		days := r.candidates(first, period)
+		if period.Equal(r.periodStart(first)) {
+			days = withDay(days, first)
+		}
+		for _, d := range days {
+			if d.Before(first) {
+				continue
+			}
+			if d.After(last) {
+				break periods
+			}
+			if !d.Equal(first) && !r.matches(first, d) {
+				continue
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				break periods
+			}
+			start := time.Date(d.Year(), d.Month(), d.Day(), e.Start.Hour(), e.Start.Minute(), e.Start.Second(), 0, e.Start.Location())
+			if !e.excluded(start) {
+				starts = append(starts, start)
+			}
+		}
+	}
+	return starts
+}
+
+// excluded reports whether an EXDATE leaves out the occurrence starting at start
+func (e Event) excluded(start time.Time) bool {
+	for _, exdate := range e.ExDates {
+		if civilDay(exdate).Equal(civilDay(start)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYSETPOS=-1",
+	} {
+		if _, err := parseRule(value); err == nil {
+			t.Errorf("parseRule(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestEventStarts(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   time.Time
+		rrule   string
+		exdates []time.Time
+		before  time.Time
+		want    string // Starts as YYYY-MM-DD, space-separated
+	}{
+		{
+			name:   "no rule",
+			start:  day(2024, time.January, 1),
+			before: day(2025, time.January, 1),
+			want:   "2024-01-01",
+		},
+		{
+			name:   "not started yet",
+			start:  day(2024, time.January, 1),
+			rrule:  "FREQ=DAILY",
+			before: day(2024, time.January, 1),
+			want:   "",
+		},
+		{
+			name:   "unreadable rule happens once",
+			start:  day(2024, time.January, 1),
+			rrule:  "FREQ=HOURLY",
+			before: day(2025, time.January, 1),
+			want:   "2024-01-01",
+		},
+		{
+			name:    "count includes excluded occurrences",
+			start:   day(2024, time.January, 1),
+			rrule:   "FREQ=DAILY;COUNT=5",
+			exdates: []time.Time{day(2024, time.January, 2)},
+			before:  day(2025, time.January, 1),
+			want:    "2024-01-01 2024-01-03 2024-01-04 2024-01-05",
+		},
+		{
+			name:    "excluded first start",
+			start:   day(2024, time.January, 1),
+			rrule:   "FREQ=WEEKLY;COUNT=3",
+			exdates: []time.Time{day(2024, time.January, 1)},
+			before:  day(2025, time.January, 1),
+			want:    "2024-01-08 2024-01-15",
+		},
+		{
+			name:   "until is inclusive",
+			start:  day(2024, time.January, 1),
+			rrule:  "FREQ=WEEKLY;UNTIL=20240122",
+			before: day(2025, time.January, 1),
+			want:   "2024-01-01 2024-01-08 2024-01-15 2024-01-22",
+		},
+		{
+			name:   "until as a UTC time",
+			start:  day(2024, time.January, 1),
+			rrule:  "FREQ=MONTHLY;UNTIL=20240401T120000Z",
+			before: day(2025, time.January, 1),
+			want:   "2024-01-01 2024-02-01 2024-03-01 2024-04-01",
+		},
+		{
+			name:   "last Sunday of the month",
+			start:  day(2024, time.January, 28),
+			rrule:  "FREQ=MONTHLY;BYDAY=-1SU",
+			before: day(2024, time.June, 1),
+			want:   "2024-01-28 2024-02-25 2024-03-31 2024-04-28 2024-05-26",
+		},
+		{
+			name:   "second Monday of May",
+			start:  day(2022, time.May, 9),
+			rrule:  "FREQ=YEARLY;BYMONTH=5;BYDAY=2MO",
+			before: day(2025, time.January, 1),
+			want:   "2022-05-09 2023-05-08 2024-05-13",
+		},
+		{
+			name:   "every other week on two days",
+			start:  day(2024, time.January, 2),
+			rrule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+			before: day(2024, time.January, 20),
+			want:   "2024-01-02 2024-01-04 2024-01-16 2024-01-18",
+		},
+		{
+			name:   "monthly on the 31st skips short months",
+			start:  day(2024, time.January, 31),
+			rrule:  "FREQ=MONTHLY",
+			before: day(2024, time.June, 1),
+			want:   "2024-01-31 2024-03-31 2024-05-31",
+		},
+		{
+			name:   "last day of the month",
+			start:  day(2024, time.January, 31),
+			rrule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			before: day(2025, time.January, 1),
+			want:   "2024-01-31 2024-02-29 2024-03-31",
+		},
+		{
+			name:   "yearly on February 29 only in leap years",
+			start:  day(2016, time.February, 29),
+			rrule:  "FREQ=YEARLY",
+			before: day(2025, time.January, 1),
+			want:   "2016-02-29 2020-02-29 2024-02-29",
+		},
+		{
+			name:   "yearly on the last day of February",
+			start:  day(2020, time.February, 29),
+			rrule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			before: day(2024, time.March, 1),
+			want:   "2020-02-29 2021-02-28 2022-02-28 2023-02-28 2024-02-29",
+		},
+		{
+			name:   "yearly on February 29 through 2100",
+			start:  day(2096, time.February, 29),
+			rrule:  "FREQ=YEARLY;INTERVAL=4",
+			before: day(2105, time.January, 1),
+			want:   "2096-02-29 2104-02-29",
+		},
+		{
+			name:   "first start counts outside the rule",
+			start:  day(2022, time.January, 15),
+			rrule:  "FREQ=YEARLY;BYMONTH=3",
+			before: day(2024, time.January, 1),
+			want:   "2022-01-15 2022-03-15 2023-03-15",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{Start: tt.start, RRule: tt.rrule, ExDates: tt.exdates, AllDay: true}
+			var got []string
+			for _, start := range event.Starts(tt.before) {
+				got = append(got, start.Format("2006-01-02"))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("Starts() = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStartsKeepsTime(t *testing.T) {
+	event := Event{Start: time.Date(2024, time.January, 1, 19, 30, 0, 0, time.UTC), RRule: "FREQ=WEEKLY;COUNT=2"}
+	starts := event.Starts(day(2025, time.January, 1))
+	want := time.Date(2024, time.January, 8, 19, 30, 0, 0, time.UTC)
+	if len(starts) != 2 || !starts[1].Equal(want) {
+		t.Errorf("Starts() = %v, want the second at %v", starts, want)
+	}
+}
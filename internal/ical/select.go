@@ -0,0 +1,148 @@
+package ical
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// ScanFolder reads the events of every .ics file in the folder. Events
+// exported by Salthaven itself are left out, as they repeat the notes.
+func ScanFolder(folderPath string, verbose bool) ([]Event, error) {
+	var events []Event
+
+	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".ics") {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			if verbose {
+				fmt.Printf("Warning: Could not read file %s: %v\n", path, err)
+			}
+			return nil // Continue processing other files
+		}
+		defer file.Close()
+
+		parsed, err := Parse(file)
+		if err != nil {
+			if verbose {
+				fmt.Printf("Warning: Could not parse calendar %s: %v\n", path, err)
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(folderPath, path)
+		if err != nil {
+			relPath = path
+		}
+		for _, event := range parsed {
+			if strings.HasSuffix(event.UID, "@salthaven") {
+				continue
+			}
+			event.Source = relPath
+			events = append(events, event)
+		}
+		return nil
+	})
+
+	return events, err
+}
+
+// Occurrence is a calendar event that happened on a day matching the reference date
+type Occurrence struct {
+	Event
+	Start time.Time // When this occurrence started
+	Day   time.Time // Day that matched, within the occurrence
+	Years int       // Years since this occurrence
+}
+
+// Icon returns an emoji marking calendar events
+func (o Occurrence) Icon() string {
+	return "🗓️"
+}
+
+// Title returns the event's summary, or its file when it has none
+func (o Occurrence) Title() string {
+	if o.Summary != "" {
+		return o.Summary
+	}
+	return o.Source
+}
+
+// When describes the occurrence's date, with the time for timed events
+func (o Occurrence) When() string {
+	if o.AllDay {
+		return o.Start.Format("January 2, 2006")
+	}
+	return o.Start.Format("January 2, 2006 3:04 PM")
+}
+
+// YearsLabel describes how long ago the occurrence was, such as "3 years ago"
+func (o Occurrence) YearsLabel() string {
+	switch o.Years {
+	case 0:
+		return "this year"
+	case 1:
+		return "1 year ago"
+	}
+	return fmt.Sprintf("%d years ago", o.Years)
+}
+
+// String renders the occurrence as a single line, such as
+// "🗓️ Dinner at Rosa's — October 18, 2019 7:00 PM (7 years ago)"
+func (o Occurrence) String() string {
+	line := fmt.Sprintf("%s %s — %s (%s)", o.Icon(), o.Title(), o.When(), o.YearsLabel())
+	if o.Location != "" {
+		line += " @ " + o.Location
+	}
+	return line
+}
+
+// lastDay returns the last calendar day an occurrence covers. Ends are
+// exclusive, so an event ending at midnight, as all-day events do, ends the day before.
+func lastDay(start, end time.Time, allDay bool) time.Time {
+	last := civilDay(end)
+	if end.After(start) && (allDay || end.Hour() == 0 && end.Minute() == 0 && end.Second() == 0) {
+		last = last.AddDate(0, 0, -1)
+	}
+	if last.Before(civilDay(start)) {
+		return civilDay(start)
+	}
+	return last
+}
+
+// Select returns the past occurrences of the events with a day matching the
+// reference date, newest first. Each occurrence is matched like a note
+// covering the days from its start to its end.
+func Select(events []Event, matcher markdown.DateMatcher, referenceDate time.Time) []Occurrence {
+	var occurrences []Occurrence
+	for _, event := range events {
+		length := event.End.Sub(event.Start)
+		for _, start := range event.Starts(referenceDate) {
+			span := markdown.DateRange{Start: civilDay(start), End: lastDay(start, start.Add(length), event.AllDay)}
+			if day, ok := span.Match(matcher, referenceDate); ok {
+				occurrences = append(occurrences, Occurrence{
+					Event: event,
+					Start: start,
+					Day:   day,
+					Years: referenceDate.Year() - start.Year(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.After(occurrences[j].Start)
+	})
+	return occurrences
+}
@@ -0,0 +1,83 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/filelock"
+)
+
+// FormatEntry renders text as a daily note line: a timestamped bullet, or an open task
+func FormatEntry(text string, at time.Time, timeFormat string, task bool) string {
+	// Keep the entry on a single line so it stays one bullet
+	text = strings.Join(strings.Fields(text), " ")
+
+	if task {
+		return "- [ ] " + text
+	}
+	if timeFormat == "" {
+		timeFormat = "HH:mm"
+	}
+	return "- " + FormatMoment(at, timeFormat) + " " + text
+}
+
+// AppendEntry adds text to the daily note for at, creating the note from its
+// template if needed. It returns the note and the line that was written.
+func AppendEntry(vaultPath string, at time.Time, text string, task bool) (DailyNote, string, error) {
+	if strings.TrimSpace(text) == "" {
+		return DailyNote{}, "", fmt.Errorf("nothing to append")
+	}
+
+	note, err := Daily(vaultPath, at, "")
+	if err != nil {
+		return note, "", err
+	}
+	templates, err := LoadTemplatesConfig(vaultPath)
+	if err != nil {
+		return note, "", err
+	}
+	entry := FormatEntry(text, at, templates.TimeFormat, task)
+
+	// Hold the lock from creating the note to writing the entry, so a note
+	// created by another writer has its template in place before we append
+	if err := os.MkdirAll(filepath.Dir(note.Path), 0755); err != nil {
+		return note, "", err
+	}
+	unlock, err := filelock.Lock(note.Path)
+	if err != nil {
+		return note, "", err
+	}
+	defer unlock()
+
+	if err := note.create(vaultPath); err != nil && !errors.Is(err, ErrNoteExists) {
+		return note, "", err
+	}
+
+	file, err := os.OpenFile(note.Path, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return note, "", err
+	}
+	defer file.Close()
+
+	// Start a new line if the note does not already end with one
+	prefix := ""
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil && err != io.EOF {
+			return note, "", err
+		}
+		if last[0] != '\n' {
+			prefix = "\n"
+		}
+	}
+
+	if _, err := file.WriteString(prefix + entry + "\n"); err != nil {
+		return note, "", err
+	}
+	return note, entry, nil
+}
@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppendEntryConcurrent(t *testing.T) {
+	vault := t.TempDir()
+	if err := os.Mkdir(filepath.Join(vault, ".obsidian"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"folder": "Journal/Daily", "format": "YYYY-MM-DD"}`
+	if err := os.WriteFile(filepath.Join(vault, ".obsidian", "daily-notes.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every writer races to create the note, then to append to it
+	const writers = 40
+	at := time.Date(2024, time.March, 5, 9, 30, 0, 0, time.Local)
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := AppendEntry(vault, at, fmt.Sprintf("entry %d", i), i%2 == 0)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("AppendEntry() error = %v", err)
+		}
+	}
+
+	path := filepath.Join(vault, "Journal", "Daily", "2024-03-05.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	header := "---\ndate: 2024-03-05T09:30\n---\n\n# 2024-03-05\n"
+	if !strings.HasPrefix(content, header) || strings.Count(content, "---\n") != 2 {
+		t.Errorf("note does not start with the template once:\n%s", content)
+	}
+	lines := strings.Split(strings.TrimPrefix(content, header), "\n")
+	if len(lines) != writers+1 || lines[writers] != "" {
+		t.Fatalf("note has %d lines after the template, want %d entries each on its own line:\n%s", len(lines), writers, content)
+	}
+	for i := 0; i < writers; i++ {
+		want := FormatEntry(fmt.Sprintf("entry %d", i), at, "", i%2 == 0)
+		if n := strings.Count(content, want+"\n"); n != 1 {
+			t.Errorf("note has %q %d times, want once", want, n)
+		}
+	}
+
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/filelock"
+)
+
+// ErrNoteExists is returned when creating a note that is already on disk
+var ErrNoteExists = errors.New("note already exists")
+
+// DailyNotesConfig mirrors the Obsidian daily notes settings (.obsidian/daily-notes.json)
+type DailyNotesConfig struct {
+	Folder   string `json:"folder"`
+	Format   string `json:"format"`
+	Template string `json:"template"`
+}
+
+// TemplatesConfig mirrors the Obsidian templates settings (.obsidian/templates.json)
+type TemplatesConfig struct {
+	Folder     string `json:"folder"`
+	DateFormat string `json:"dateFormat"`
+	TimeFormat string `json:"timeFormat"`
+}
+
+// readObsidianConfig decodes a JSON settings file from the vault's .obsidian folder
+func readObsidianConfig(vaultPath, name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(vaultPath, ".obsidian", name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Obsidian omits settings that were never changed
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
+// LoadDailyNotesConfig reads the vault's daily notes settings, applying Obsidian's defaults
+func LoadDailyNotesConfig(vaultPath string) (DailyNotesConfig, error) {
+	var config DailyNotesConfig
+	if err := readObsidianConfig(vaultPath, "daily-notes.json", &config); err != nil {
+		return config, err
+	}
+	if config.Format == "" {
+		config.Format = "YYYY-MM-DD"
+	}
+	return config, nil
+}
+
+// LoadTemplatesConfig reads the vault's templates settings
+func LoadTemplatesConfig(vaultPath string) (TemplatesConfig, error) {
+	var config TemplatesConfig
+	err := readObsidianConfig(vaultPath, "templates.json", &config)
+	return config, err
+}
+
+// NotePath returns the path of the daily note for the given date
+func (c DailyNotesConfig) NotePath(vaultPath string, date time.Time) string {
+	name := FormatMoment(date, c.Format) + ".md"
+	return filepath.Join(vaultPath, filepath.FromSlash(c.Folder), filepath.FromSlash(name))
+}
+
+// resolveTemplatePath turns a vault-relative template reference into a file path
+func resolveTemplatePath(vaultPath, template string) string {
+	path := filepath.FromSlash(template)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(vaultPath, path)
+	}
+	if filepath.Ext(path) == "" {
+		path += ".md"
+	}
+	return path
+}
+
+// DailyNote describes the daily note for one date
+type DailyNote struct {
+	Path         string
+	Date         time.Time
+	TemplatePath string // Template file, or empty for DefaultTemplate
+}
+
+// Daily resolves the daily note for date using the vault's settings.
+// templateOverride replaces the configured template when set.
+func Daily(vaultPath string, date time.Time, templateOverride string) (DailyNote, error) {
+	config, err := LoadDailyNotesConfig(vaultPath)
+	if err != nil {
+		return DailyNote{}, err
+	}
+
+	note := DailyNote{
+		Path: config.NotePath(vaultPath, date),
+		Date: date,
+	}
+
+	template := config.Template
+	if templateOverride != "" {
+		template = templateOverride
+	}
+	if template != "" {
+		note.TemplatePath = resolveTemplatePath(vaultPath, template)
+	}
+
+	return note, nil
+}
+
+// Render expands the note's template
+func (n DailyNote) Render(vaultPath string) (string, error) {
+	tmpl := DefaultTemplate
+	if n.TemplatePath != "" {
+		content, err := os.ReadFile(n.TemplatePath)
+		if err != nil {
+			return "", fmt.Errorf("could not read template: %v", err)
+		}
+		tmpl = string(content)
+	}
+
+	templates, err := LoadTemplatesConfig(vaultPath)
+	if err != nil {
+		return "", err
+	}
+
+	return ExpandTemplate(tmpl, TemplateVars{
+		Title:      strings.TrimSuffix(filepath.Base(n.Path), filepath.Ext(n.Path)),
+		Date:       n.Date,
+		DateFormat: templates.DateFormat,
+		TimeFormat: templates.TimeFormat,
+	}), nil
+}
+
+// Create writes the daily note from its template, refusing to overwrite an existing file
+func (n DailyNote) Create(vaultPath string) error {
+	if err := os.MkdirAll(filepath.Dir(n.Path), 0755); err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(n.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return n.create(vaultPath)
+}
+
+// create writes the note from its template, the caller holding its lock
+func (n DailyNote) create(vaultPath string) error {
+	content, err := n.Render(vaultPath)
+	if err != nil {
+		return err
+	}
+
+	// O_EXCL makes the existence check and the create a single step
+	file, err := os.OpenFile(n.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%w: %s", ErrNoteExists, n.Path)
+		}
+		return err
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,197 @@
+package journal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate is used when the vault has no daily note template configured.
+// The frontmatter date has fixed formats so it stays readable by ParseDate
+// whatever display formats templates.json sets.
+const DefaultTemplate = `---
+date: {{date:YYYY-MM-DD}}T{{time:HH:mm}}
+---
+
+# {{title}}
+`
+
+// TemplateVars holds the values substituted into a note template
+type TemplateVars struct {
+	Title      string
+	Date       time.Time
+	DateFormat string // Moment.js format used for {{date}}
+	TimeFormat string // Moment.js format used for {{time}}
+}
+
+// placeholderRegex matches {{date}}, {{time}}, {{title}} and the {{date:FORMAT}} / {{time:FORMAT}} variants
+var placeholderRegex = regexp.MustCompile(`\{\{\s*(date|time|title)\s*(?::([^}]*))?\}\}`)
+
+// ExpandTemplate replaces the Obsidian template placeholders in tmpl
+func ExpandTemplate(tmpl string, vars TemplateVars) string {
+	dateFormat := vars.DateFormat
+	if dateFormat == "" {
+		dateFormat = "YYYY-MM-DD"
+	}
+	timeFormat := vars.TimeFormat
+	if timeFormat == "" {
+		timeFormat = "HH:mm"
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(tmpl, func(match string) string {
+		parts := placeholderRegex.FindStringSubmatch(match)
+		name, format := parts[1], strings.TrimSpace(parts[2])
+
+		switch name {
+		case "title":
+			return vars.Title
+		case "date":
+			if format == "" {
+				format = dateFormat
+			}
+		case "time":
+			if format == "" {
+				format = timeFormat
+			}
+		}
+		return FormatMoment(vars.Date, format)
+	})
+}
+
+// momentTokens lists the supported Moment.js tokens, longest first so that
+// "MMMM" is not consumed as "MM" + "MM"
+var momentTokens = []string{
+	"YYYY", "GGGG", "MMMM", "dddd", "DDDD",
+	"MMM", "ddd", "DDD",
+	"YY", "MM", "DD", "Do", "dd", "HH", "hh", "mm", "ss", "WW", "ww",
+	"Q", "M", "D", "d", "H", "h", "m", "s", "A", "a", "W", "w",
+}
+
+// FormatMoment formats t using a Moment.js format string, the syntax Obsidian
+// uses for daily note names and template dates. Text in [brackets] is copied literally.
+func FormatMoment(t time.Time, format string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end > 0 {
+				sb.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+
+		matched := false
+		for _, token := range momentTokens {
+			if strings.HasPrefix(format[i:], token) {
+				sb.WriteString(formatMomentToken(t, token))
+				i += len(token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteByte(format[i])
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+// formatMomentToken renders a single Moment.js token
+func formatMomentToken(t time.Time, token string) string {
+	isoYear, isoWeek := t.ISOWeek()
+
+	switch token {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year())
+	case "YY":
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case "GGGG":
+		return fmt.Sprintf("%04d", isoYear)
+	case "Q":
+		return fmt.Sprintf("%d", (int(t.Month())-1)/3+1)
+	case "MMMM":
+		return t.Month().String()
+	case "MMM":
+		return t.Month().String()[:3]
+	case "MM":
+		return fmt.Sprintf("%02d", int(t.Month()))
+	case "M":
+		return fmt.Sprintf("%d", int(t.Month()))
+	case "DDDD":
+		return fmt.Sprintf("%03d", t.YearDay())
+	case "DDD":
+		return fmt.Sprintf("%d", t.YearDay())
+	case "DD":
+		return fmt.Sprintf("%02d", t.Day())
+	case "Do":
+		return ordinal(t.Day())
+	case "D":
+		return fmt.Sprintf("%d", t.Day())
+	case "dddd":
+		return t.Weekday().String()
+	case "ddd":
+		return t.Weekday().String()[:3]
+	case "dd":
+		return t.Weekday().String()[:2]
+	case "d":
+		return fmt.Sprintf("%d", int(t.Weekday()))
+	case "HH":
+		return fmt.Sprintf("%02d", t.Hour())
+	case "H":
+		return fmt.Sprintf("%d", t.Hour())
+	case "hh":
+		return fmt.Sprintf("%02d", hour12(t.Hour()))
+	case "h":
+		return fmt.Sprintf("%d", hour12(t.Hour()))
+	case "mm":
+		return fmt.Sprintf("%02d", t.Minute())
+	case "m":
+		return fmt.Sprintf("%d", t.Minute())
+	case "ss":
+		return fmt.Sprintf("%02d", t.Second())
+	case "s":
+		return fmt.Sprintf("%d", t.Second())
+	case "A":
+		if t.Hour() < 12 {
+			return "AM"
+		}
+		return "PM"
+	case "a":
+		if t.Hour() < 12 {
+			return "am"
+		}
+		return "pm"
+	case "WW", "ww":
+		return fmt.Sprintf("%02d", isoWeek)
+	case "W", "w":
+		return fmt.Sprintf("%d", isoWeek)
+	}
+	return token
+}
+
+func hour12(h int) int {
+	if h%12 == 0 {
+		return 12
+	}
+	return h % 12
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatMoment(t *testing.T) {
+	// A Tuesday afternoon, and New Year's Day 2021, which falls in ISO week 53 of 2020
+	afternoon := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	newYear := time.Date(2021, time.January, 1, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		t      time.Time
+		format string
+		want   string
+	}{
+		{afternoon, "YYYY-MM-DD", "2024-03-05"},
+		{afternoon, "YY/M/D", "24/3/5"},
+		{afternoon, "MMMM MMM", "March Mar"},
+		{afternoon, "dddd ddd dd d", "Tuesday Tue Tu 2"},
+		{afternoon, "DDDD DDD Q", "065 65 1"},
+		{afternoon, "HH:mm:ss", "14:07:09"},
+		{afternoon, "H:m:s", "14:7:9"},
+		{afternoon, "hh:mm A", "02:07 PM"},
+		{afternoon, "h a", "2 pm"},
+		{afternoon, "GGGG-[W]WW", "2024-W10"},
+		{afternoon, "w", "10"},
+		{afternoon, "dddd, MMMM Do YYYY", "Tuesday, March 5th 2024"},
+		{newYear, "GGGG-[W]WW YYYY", "2020-W53 2021"},
+		{newYear, "h:mm a", "12:30 am"},
+
+		// Ordinals
+		{time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Do", "1st"},
+		{time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), "Do", "2nd"},
+		{time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC), "Do", "3rd"},
+		{time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), "Do", "11th"},
+		{time.Date(2024, time.March, 12, 0, 0, 0, 0, time.UTC), "Do", "12th"},
+		{time.Date(2024, time.March, 13, 0, 0, 0, 0, time.UTC), "Do", "13th"},
+		{time.Date(2024, time.March, 22, 0, 0, 0, 0, time.UTC), "Do", "22nd"},
+		{time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), "Do", "31st"},
+
+		// Escaped text
+		{afternoon, "[Journal]/YYYY/[Week] WW", "Journal/2024/Week 10"},
+		{afternoon, "[YYYY-MM-DD]", "YYYY-MM-DD"},
+		{afternoon, "[]YYYY", "2024"},
+		{afternoon, "[YYYY", "[2024"},
+		{afternoon, "", ""},
+	}
+	for _, tt := range tests {
+		if got := FormatMoment(tt.t, tt.format); got != tt.want {
+			t.Errorf("FormatMoment(%s, %q) = %q, want %q", tt.t.Format(time.RFC3339), tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 9, 5, 0, 0, time.UTC)
+	tests := []struct {
+		tmpl string
+		vars TemplateVars
+		want string
+	}{
+		{
+			DefaultTemplate,
+			TemplateVars{Title: "2024-03-05", Date: date},
+			"---\ndate: 2024-03-05T09:05\n---\n\n# 2024-03-05\n",
+		},
+		{
+			// The default template's frontmatter ignores the display formats
+			DefaultTemplate,
+			TemplateVars{Title: "Tuesday", Date: date, DateFormat: "dddd", TimeFormat: "h:mm A"},
+			"---\ndate: 2024-03-05T09:05\n---\n\n# Tuesday\n",
+		},
+		{
+			"{{date}} {{time}} {{title}}",
+			TemplateVars{Title: "Today", Date: date},
+			"2024-03-05 09:05 Today",
+		},
+		{
+			"{{date}} at {{ time }}",
+			TemplateVars{Date: date, DateFormat: "MMMM Do", TimeFormat: "h:mm A"},
+			"March 5th at 9:05 AM",
+		},
+		{
+			"{{date:dddd}} {{time: HH[h]mm }} {{unknown}}",
+			TemplateVars{Date: date},
+			"Tuesday 09h05 {{unknown}}",
+		},
+	}
+	for _, tt := range tests {
+		if got := ExpandTemplate(tt.tmpl, tt.vars); got != tt.want {
+			t.Errorf("ExpandTemplate(%q, %+v) = %q, want %q", tt.tmpl, tt.vars, got, tt.want)
+		}
+	}
+}
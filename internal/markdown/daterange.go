@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRangeDays bounds how many days of a date range are checked against a matcher
+const maxRangeDays = 3660
+
+// DateRange is the span of days a note covers. Single-day notes have Start equal to End.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewDateRange returns the range from start to end, which must not be before start
+func NewDateRange(start, end time.Time) (DateRange, error) {
+	if civilDate(end).Before(civilDate(start)) {
+		return DateRange{}, fmt.Errorf("date range ends before it starts: %s..%s",
+			start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	return DateRange{Start: start, End: end}, nil
+}
+
+// Days returns the number of calendar days in the range, counting both ends
+func (r DateRange) Days() int {
+	return daysBetween(r.Start, r.End) + 1
+}
+
+// Multiday reports whether the range covers more than one day
+func (r DateRange) Multiday() bool {
+	return r.Days() > 1
+}
+
+// DayNumber returns which day of the range t is, starting from 1
+func (r DateRange) DayNumber(t time.Time) int {
+	return daysBetween(r.Start, t) + 1
+}
+
+// Match returns the first day of the range that matches the reference date
+func (r DateRange) Match(matcher DateMatcher, referenceDate time.Time) (time.Time, bool) {
+	days := r.Days()
+	if days > maxRangeDays {
+		days = maxRangeDays
+	}
+
+	for i := 0; i < days; i++ {
+		day := r.Start.AddDate(0, 0, i)
+		if matcher(day, referenceDate) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Digest gathers the notes of a whole period from previous years
+type Digest string
+
+// Digest periods
+const (
+	DigestNone  Digest = ""
+	DigestWeek  Digest = "week"  // Same ISO week
+	DigestMonth Digest = "month" // Same calendar month
+)
+
+// ParseDigest parses a digest period name; an empty name means no digest
+func ParseDigest(name string) (Digest, error) {
+	switch digest := Digest(strings.ToLower(strings.TrimSpace(name))); digest {
+	case DigestNone, DigestWeek, DigestMonth:
+		return digest, nil
+	}
+	return DigestNone, fmt.Errorf("unknown digest %q (want week or month)", name)
+}
+
+// Year returns the year a date belongs to for the digest. Week digests use the
+// ISO year, so December 30 can belong to week 1 of the following year.
+func (d Digest) Year(t time.Time) int {
+	if d == DigestWeek {
+		year, _ := t.ISOWeek()
+		return year
+	}
+	return t.Year()
+}
+
+// Matcher returns a matcher for notes in the same period of an earlier year
+func (d Digest) Matcher() DateMatcher {
+	period := SameMonthMatcher
+	if d == DigestWeek {
+		period = SameWeekMatcher
+	}
+	return func(fileDate, referenceDate time.Time) bool {
+		return d.Year(fileDate) < d.Year(referenceDate) && period(fileDate, referenceDate)
+	}
+}
+
+// Label describes the period containing the reference date, such as "Week 42" or "October"
+func (d Digest) Label(referenceDate time.Time) string {
+	if d == DigestWeek {
+		_, week := referenceDate.ISOWeek()
+		return fmt.Sprintf("Week %d", week)
+	}
+	return referenceDate.Format("January")
+}
+
+// YearGroup holds the notes of one year
+type YearGroup struct {
+	Year  int
+	Notes []Match
+}
+
+// GroupByYear groups notes by the year yearOf assigns their matching day,
+// newest year first and oldest note first within a year
+func GroupByYear(notes []Match, yearOf func(time.Time) int) []YearGroup {
+	byYear := make(map[int][]Match)
+	for _, note := range notes {
+		year := yearOf(note.Day)
+		byYear[year] = append(byYear[year], note)
+	}
+
+	var groups []YearGroup
+	for year, yearNotes := range byYear {
+		sort.Slice(yearNotes, func(i, j int) bool {
+			return yearNotes[i].Day.Before(yearNotes[j].Day)
+		})
+		groups = append(groups, YearGroup{Year: year, Notes: yearNotes})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Year > groups[j].Year
+	})
+	return groups
+}
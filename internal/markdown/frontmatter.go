@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"strings"
+)
+
+// ParseFrontmatter extracts the properties of a note's YAML frontmatter. It
+// understands the subset of YAML that Obsidian writes: scalar values, inline
+// lists ([a, b]) and block lists (- a). Every value is returned as a list.
+func ParseFrontmatter(content string) map[string][]string {
+	properties := make(map[string][]string)
+	if !strings.HasPrefix(content, "---") {
+		return properties
+	}
+
+	lines := strings.Split(content, "\n")
+	currentKey := ""
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if i == 0 {
+			if trimmed != "---" {
+				return properties
+			}
+			continue
+		}
+		if trimmed == "---" {
+			break
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Block list item belonging to the previous key
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if currentKey != "" {
+				item := unquote(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+				if item != "" {
+					properties[currentKey] = append(properties[currentKey], item)
+				}
+			}
+			continue
+		}
+
+		// Nested mappings are not supported: indented keys are read as if they
+		// were top-level, as the date parser always has, the first one winning
+		colon := strings.Index(trimmed, ":")
+		if colon <= 0 {
+			currentKey = ""
+			continue
+		}
+		currentKey = strings.TrimSpace(trimmed[:colon])
+		value := strings.TrimSpace(trimmed[colon+1:])
+
+		if _, ok := properties[currentKey]; !ok {
+			properties[currentKey] = nil
+		}
+		if value == "" {
+			continue
+		}
+
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					properties[currentKey] = append(properties[currentKey], item)
+				}
+			}
+		} else {
+			properties[currentKey] = append(properties[currentKey], unquote(value))
+		}
+	}
+
+	return properties
+}
+
+// unquote removes matching single or double quotes around a YAML scalar
+func unquote(value string) string {
+	if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
+		(value[0] == '\'' && value[len(value)-1] == '\'')) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
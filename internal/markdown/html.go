@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Inline markup, matched after the text is HTML-escaped
+var (
+	htmlCodeRegex     = regexp.MustCompile("`([^`]+)`")
+	htmlBoldRegex     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	htmlItalicRegex   = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	htmlLinkRegex     = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)\)`)
+	htmlWikilinkRegex = regexp.MustCompile(`!?\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	htmlHeadingRegex  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	htmlBulletRegex   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	htmlNumberedRegex = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	htmlTaskRegex     = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+)
+
+// inlineHTML escapes a line of text and converts its inline markup. Links to
+// web pages stay links; wikilinks and local links show their text only, as
+// they point into the vault.
+func inlineHTML(text string) string {
+	// Keep code spans out of the other conversions
+	var codes []string
+	text = htmlCodeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		codes = append(codes, "<code>"+html.EscapeString(match[1:len(match)-1])+"</code>")
+		return "\x00"
+	})
+
+	text = html.EscapeString(text)
+	text = htmlWikilinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := htmlWikilinkRegex.FindStringSubmatch(match)
+		if parts[2] != "" {
+			return parts[2]
+		}
+		return parts[1]
+	})
+	text = htmlLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := htmlLinkRegex.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[2], "http://") || strings.HasPrefix(parts[2], "https://") {
+			return `<a href="` + parts[2] + `">` + parts[1] + `</a>`
+		}
+		return parts[1]
+	})
+	text = htmlBoldRegex.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = htmlItalicRegex.ReplaceAllString(text, "<em>$1$2</em>")
+
+	for _, code := range codes {
+		text = strings.Replace(text, "\x00", code, 1)
+	}
+	return text
+}
+
+// ToHTML renders a note's body as simple HTML: headings, paragraphs, lists,
+// task lists, quotes and code blocks, with bold, italic, code and links inline.
+// It covers what journal entries use rather than all of Markdown.
+func ToHTML(body string) string {
+	var b strings.Builder
+	var paragraph []string
+	list := "" // "ul" or "ol" while a list is open
+	inCode := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		flush()
+		if list != kind {
+			closeList()
+			b.WriteString("<" + kind + ">\n")
+			list = kind
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+			} else {
+				flush()
+				closeList()
+				b.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			closeList()
+		} else if m := htmlHeadingRegex.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			closeList()
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + inlineHTML(m[2]) + "</h" + level + ">\n")
+		} else if m := htmlBulletRegex.FindStringSubmatch(line); m != nil {
+			openList("ul")
+			item := m[1]
+			if task := htmlTaskRegex.FindStringSubmatch(item); task != nil {
+				box := "☐"
+				if task[1] != " " {
+					box = "☑"
+				}
+				b.WriteString("<li>" + box + " " + inlineHTML(task[2]) + "</li>\n")
+			} else {
+				b.WriteString("<li>" + inlineHTML(item) + "</li>\n")
+			}
+		} else if m := htmlNumberedRegex.FindStringSubmatch(line); m != nil {
+			openList("ol")
+			b.WriteString("<li>" + inlineHTML(m[1]) + "</li>\n")
+		} else if strings.HasPrefix(trimmed, ">") {
+			flush()
+			closeList()
+			b.WriteString("<blockquote>" + inlineHTML(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))) + "</blockquote>\n")
+		} else {
+			closeList()
+			paragraph = append(paragraph, inlineHTML(trimmed))
+		}
+	}
+
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flush()
+	closeList()
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LeapDayPolicy decides when notes written on February 29 come up in years without one
+type LeapDayPolicy string
+
+// Leap day policies
+const (
+	LeapDayExact LeapDayPolicy = "exact" // Only on February 29, so once every four years
+	LeapDayFeb28 LeapDayPolicy = "feb28" // On February 28 in non-leap years
+	LeapDayMar1  LeapDayPolicy = "mar1"  // On March 1 in non-leap years
+	LeapDayBoth  LeapDayPolicy = "both"  // On both February 28 and March 1 in non-leap years
+)
+
+// DefaultLeapDayPolicy is used when no policy is configured
+const DefaultLeapDayPolicy = LeapDayFeb28
+
+// ParseLeapDayPolicy parses a policy name; an empty name gives the default policy
+func ParseLeapDayPolicy(name string) (LeapDayPolicy, error) {
+	switch policy := LeapDayPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return DefaultLeapDayPolicy, nil
+	case LeapDayExact, LeapDayFeb28, LeapDayMar1, LeapDayBoth:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown leap day policy %q (want exact, feb28, mar1 or both)", name)
+}
+
+// IsLeapYear reports whether year has a February 29
+func IsLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// isLeapDay reports whether t is February 29
+func isLeapDay(t time.Time) bool {
+	return t.Month() == time.February && t.Day() == 29
+}
+
+// LeapDayStandIns returns the days a February 29 is observed on in the given year
+func (p LeapDayPolicy) LeapDayStandIns(year int) []time.Time {
+	if IsLeapYear(year) {
+		return []time.Time{time.Date(year, time.February, 29, 0, 0, 0, 0, time.UTC)}
+	}
+
+	feb28 := time.Date(year, time.February, 28, 0, 0, 0, 0, time.UTC)
+	mar1 := time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC)
+	switch p {
+	case LeapDayFeb28:
+		return []time.Time{feb28}
+	case LeapDayMar1:
+		return []time.Time{mar1}
+	case LeapDayBoth:
+		return []time.Time{feb28, mar1}
+	}
+	return nil
+}
+
+// SameDayMatcherFor returns a same-day matcher that shows February 29 notes
+// in non-leap reference years according to policy
+func SameDayMatcherFor(policy LeapDayPolicy) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		if SameDayMatcher(fileDate, referenceDate) {
+			return true
+		}
+		if !isLeapDay(fileDate) {
+			return false
+		}
+		for _, day := range policy.LeapDayStandIns(referenceDate.Year()) {
+			if SameDayMatcher(day, referenceDate) {
+				return true
+			}
+		}
+		return false
+	}
+}
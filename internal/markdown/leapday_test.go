@@ -0,0 +1,134 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+// leapDayNote is the date of a note written on February 29
+var leapDayNote = date(2020, time.February, 29)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// leapDayTests are the reference dates around February 29 in a non-leap
+// year, a leap year and a century that is not a leap year, with whether each
+// policy shows a February 29 note on them
+var leapDayTests = []struct {
+	reference time.Time
+	want      map[LeapDayPolicy]bool
+}{
+	{date(2023, time.February, 28), map[LeapDayPolicy]bool{LeapDayFeb28: true, LeapDayBoth: true}},
+	{date(2023, time.March, 1), map[LeapDayPolicy]bool{LeapDayMar1: true, LeapDayBoth: true}},
+	{date(2024, time.February, 28), map[LeapDayPolicy]bool{}},
+	{date(2024, time.February, 29), map[LeapDayPolicy]bool{LeapDayExact: true, LeapDayFeb28: true, LeapDayMar1: true, LeapDayBoth: true}},
+	{date(2024, time.March, 1), map[LeapDayPolicy]bool{}},
+	{date(2100, time.February, 28), map[LeapDayPolicy]bool{LeapDayFeb28: true, LeapDayBoth: true}},
+	{date(2100, time.March, 1), map[LeapDayPolicy]bool{LeapDayMar1: true, LeapDayBoth: true}},
+}
+
+var leapDayPolicies = []LeapDayPolicy{LeapDayExact, LeapDayFeb28, LeapDayMar1, LeapDayBoth}
+
+func TestParseLeapDayPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    LeapDayPolicy
+		wantErr bool
+	}{
+		{"", DefaultLeapDayPolicy, false},
+		{"exact", LeapDayExact, false},
+		{"feb28", LeapDayFeb28, false},
+		{" Mar1 ", LeapDayMar1, false},
+		{"BOTH", LeapDayBoth, false},
+		{"feb29", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLeapDayPolicy(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLeapDayPolicy(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsLeapYear(t *testing.T) {
+	for year, want := range map[int]bool{2020: true, 2023: false, 2024: true, 2000: true, 2100: false} {
+		if got := IsLeapYear(year); got != want {
+			t.Errorf("IsLeapYear(%d) = %v, want %v", year, got, want)
+		}
+	}
+}
+
+func TestSameDayMatcherFor(t *testing.T) {
+	for _, policy := range leapDayPolicies {
+		matcher := SameDayMatcherFor(policy)
+		for _, tt := range leapDayTests {
+			if got := matcher(leapDayNote, tt.reference); got != tt.want[policy] {
+				t.Errorf("%s: February 29 note on %s = %v, want %v", policy, tt.reference.Format("2006-01-02"), got, tt.want[policy])
+			}
+		}
+
+		// Notes from other days keep to their own day, whatever the policy
+		feb28, mar1 := date(2019, time.February, 28), date(2019, time.March, 1)
+		if matcher(feb28, date(2024, time.February, 29)) || matcher(mar1, date(2024, time.February, 29)) {
+			t.Errorf("%s: February 28 or March 1 note shown on February 29", policy)
+		}
+		if !matcher(feb28, date(2100, time.February, 28)) || !matcher(mar1, date(2100, time.March, 1)) {
+			t.Errorf("%s: February 28 or March 1 note not shown on its own day", policy)
+		}
+	}
+}
+
+func TestParseMatcherLeapDay(t *testing.T) {
+	for _, policy := range leapDayPolicies {
+		sameDay, err := ParseMatcher("sameday", policy)
+		if err != nil {
+			t.Fatalf("ParseMatcher(sameday, %s) error = %v", policy, err)
+		}
+		defaultMatcher, err := ParseMatcher("", policy)
+		if err != nil {
+			t.Fatalf("ParseMatcher(\"\", %s) error = %v", policy, err)
+		}
+		yearsAgo, err := ParseMatcher("yearsago:3", policy)
+		if err != nil {
+			t.Fatalf("ParseMatcher(yearsago:3, %s) error = %v", policy, err)
+		}
+		for _, tt := range leapDayTests {
+			if got := sameDay(leapDayNote, tt.reference); got != tt.want[policy] {
+				t.Errorf("%s: sameday on %s = %v, want %v", policy, tt.reference.Format("2006-01-02"), got, tt.want[policy])
+			}
+			if got := defaultMatcher(leapDayNote, tt.reference); got != tt.want[policy] {
+				t.Errorf("%s: default expression on %s = %v, want %v", policy, tt.reference.Format("2006-01-02"), got, tt.want[policy])
+			}
+
+			// yearsago counts from the note's year to the reference year
+			want := tt.want[policy] && tt.reference.Year() == 2023
+			if got := yearsAgo(leapDayNote, tt.reference); got != want {
+				t.Errorf("%s: yearsago:3 on %s = %v, want %v", policy, tt.reference.Format("2006-01-02"), got, want)
+			}
+		}
+	}
+}
+
+func TestDigestMatcherLeapDay(t *testing.T) {
+	// Digests cover whole periods, so February 29 notes need no stand-in day
+	tests := []struct {
+		digest    Digest
+		reference time.Time
+		want      bool
+	}{
+		{DigestMonth, date(2023, time.February, 1), true},
+		{DigestMonth, date(2024, time.February, 29), true},
+		{DigestMonth, date(2100, time.February, 28), true},
+		{DigestMonth, date(2100, time.March, 1), false},
+		{DigestWeek, date(2023, time.March, 1), true}, // Week 9, like February 29, 2020
+		{DigestWeek, date(2024, time.February, 29), true},
+		{DigestWeek, date(2100, time.February, 20), false},
+		{DigestMonth, date(2020, time.February, 1), false}, // Only earlier years
+	}
+	for _, tt := range tests {
+		if got := tt.digest.Matcher()(leapDayNote, tt.reference); got != tt.want {
+			t.Errorf("%s digest on %s = %v, want %v", tt.digest, tt.reference.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// wikilinkRegex matches [[target]], [[target|display]] and ![[embed]] links
+var wikilinkRegex = regexp.MustCompile(`(!?)\[\[([^\[\]]+)\]\]`)
+
+// markdownLinkRegex matches [text](target) links and ![alt](target) embeds
+var markdownLinkRegex = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\(([^()\s]+)\)`)
+
+// Link is a link from a note to another note or attachment
+type Link struct {
+	Target  string // Linked note or file as written, without any #heading
+	Display string // Link text, or the target when there is none
+	Embed   bool   // ![[embed]] rather than a plain link
+	Line    int    // Zero-based line in the file
+}
+
+// ExtractLinks returns the wikilinks and local markdown links in a note,
+// including wikilinks in frontmatter properties such as people: "[[Alice]]".
+// Links in code are ignored, as are links to web pages and links within the note.
+func ExtractLinks(content string) []Link {
+	var links []Link
+	inCodeBlock := false
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+		line = inlineCodeRegex.ReplaceAllString(line, "")
+
+		for _, match := range wikilinkRegex.FindAllStringSubmatch(line, -1) {
+			target, display, _ := strings.Cut(match[2], "|")
+			if link, ok := newLink(target, display, match[1] == "!", i); ok {
+				links = append(links, link)
+			}
+		}
+
+		for _, match := range markdownLinkRegex.FindAllStringSubmatch(line, -1) {
+			target := match[3]
+			if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+				continue // Web and other external links
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			if link, ok := newLink(target, match[2], match[1] == "!", i); ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// newLink builds a link, dropping any #heading or #^block from the target.
+// It reports false for links to a heading of the same note.
+func newLink(target, display string, embed bool, line int) (Link, bool) {
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return Link{}, false
+	}
+
+	display = strings.TrimSpace(display)
+	if display == "" {
+		display = target
+	}
+	return Link{Target: target, Display: display, Embed: embed, Line: line}, true
+}
@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"time"
+)
+
+// civilDate strips the time of day and location, so dates compare by calendar day
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	return int(civilDate(b).Sub(civilDate(a)).Hours() / 24)
+}
+
+// SameWeekMatcher returns true if the file date falls in the same ISO week number (any year)
+func SameWeekMatcher(fileDate, referenceDate time.Time) bool {
+	_, fileWeek := fileDate.ISOWeek()
+	_, referenceWeek := referenceDate.ISOWeek()
+	return fileWeek == referenceWeek
+}
+
+// SameMonthMatcher returns true if the file date falls in the same calendar month (any year)
+func SameMonthMatcher(fileDate, referenceDate time.Time) bool {
+	return fileDate.Month() == referenceDate.Month()
+}
+
+// WindowMatcher returns a matcher for file dates within days of the reference
+// month and day in any year. Windows wrap around the new year, so late
+// December notes match early January reference dates.
+func WindowMatcher(days int) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		offset := AnniversaryOffset(fileDate, referenceDate)
+		return offset >= -days && offset <= days
+	}
+}
+
+// AnniversaryOffset returns the days from the reference month and day to the
+// file date, in whichever year brings them closest: 3 for a note written three
+// days after the date in some year, -2 for two days before. It wraps around
+// the new year, so December 30 is -3 days from January 2.
+func AnniversaryOffset(fileDate, referenceDate time.Time) int {
+	best := 0
+	for i, year := range []int{fileDate.Year() - 1, fileDate.Year(), fileDate.Year() + 1} {
+		anniversary := time.Date(year, referenceDate.Month(), referenceDate.Day(), 0, 0, 0, 0, time.UTC)
+		offset := daysBetween(anniversary, fileDate)
+		if i == 0 || abs(offset) < abs(best) {
+			best = offset
+		}
+	}
+	return best
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// YearsAgoMatcher returns a matcher for file dates exactly the given number of
+// years before the reference date, observing February 29 according to policy
+func YearsAgoMatcher(years int, policy LeapDayPolicy) DateMatcher {
+	sameDay := SameDayMatcherFor(policy)
+	return func(fileDate, referenceDate time.Time) bool {
+		return fileDate.Year() == referenceDate.Year()-years && sameDay(fileDate, referenceDate)
+	}
+}
+
+// weekdayOrdinal returns which occurrence of its weekday within the month t is (1 for the first Monday, ...)
+func weekdayOrdinal(t time.Time) int {
+	return (t.Day()-1)/7 + 1
+}
+
+// WeekdayOfMonthMatcher returns true if the file date is the same weekday occurrence in the
+// same month as the reference date, such as the second Tuesday of October (any year)
+func WeekdayOfMonthMatcher(fileDate, referenceDate time.Time) bool {
+	return fileDate.Month() == referenceDate.Month() &&
+		fileDate.Weekday() == referenceDate.Weekday() &&
+		weekdayOrdinal(fileDate) == weekdayOrdinal(referenceDate)
+}
+
+// RangeMatcher returns a matcher for file dates between from and to inclusive,
+// whatever the reference date. A zero from or to leaves that end open.
+func RangeMatcher(from, to time.Time) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		day := civilDate(fileDate)
+		if !from.IsZero() && day.Before(civilDate(from)) {
+			return false
+		}
+		if !to.IsZero() && day.After(civilDate(to)) {
+			return false
+		}
+		return true
+	}
+}
+
+// And returns a matcher that requires every matcher to match
+func And(matchers ...DateMatcher) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		for _, matcher := range matchers {
+			if !matcher(fileDate, referenceDate) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a matcher that requires any matcher to match
+func Or(matchers ...DateMatcher) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		for _, matcher := range matchers {
+			if matcher(fileDate, referenceDate) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a matcher that inverts matcher
+func Not(matcher DateMatcher) DateMatcher {
+	return func(fileDate, referenceDate time.Time) bool {
+		return !matcher(fileDate, referenceDate)
+	}
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"fmt"
+	"time"
+)
+
+// NearestMatch is a note dated near, rather than on, the reference date
+type NearestMatch struct {
+	Match
+	Offset int // Days from the reference month and day; negative is before
+}
+
+// OffsetLabel describes the offset, such as "3 days later" or "1 day earlier"
+func (m NearestMatch) OffsetLabel() string {
+	return OffsetLabel(m.Offset)
+}
+
+// OffsetLabel describes a number of days from the reference date
+func OffsetLabel(offset int) string {
+	unit := "days"
+	if abs(offset) == 1 {
+		unit = "day"
+	}
+	switch {
+	case offset > 0:
+		return fmt.Sprintf("%d %s later", offset, unit)
+	case offset < 0:
+		return fmt.Sprintf("%d %s earlier", -offset, unit)
+	}
+	return "same day"
+}
+
+// SelectNearest returns the notes, from any year, dated closest to the
+// reference month and day within window days either side, for when nothing
+// falls on the date itself. Every note at the smallest distance is returned,
+// those before the date first.
+func SelectNearest(notes []Note, window int, referenceDate time.Time, tags TagFilter) []NearestMatch {
+	var nearest []NearestMatch
+	bestDistance := window + 1
+
+	for _, note := range notes {
+		if !note.Dated() || !tags.Matches(note.Tags) {
+			continue
+		}
+
+		// The day of any date property closest to the reference date
+		found := false
+		var best NearestMatch
+		for _, date := range note.Dates {
+			days := date.Days()
+			if days > maxRangeDays {
+				days = maxRangeDays
+			}
+			for i := 0; i < days; i++ {
+				day := date.Start.AddDate(0, 0, i)
+				offset := AnniversaryOffset(day, referenceDate)
+				if !found || abs(offset) < abs(best.Offset) {
+					found = true
+					best = NearestMatch{Match: Match{Note: note, Source: date, Day: day}, Offset: offset}
+				}
+			}
+		}
+
+		distance := abs(best.Offset)
+		switch {
+		case !found || distance > window || distance > bestDistance:
+			continue
+		case distance < bestDistance:
+			bestDistance = distance
+			nearest = nil
+		}
+		nearest = append(nearest, best)
+	}
+
+	// Earlier days first, keeping scan order within a day
+	var ordered []NearestMatch
+	for _, match := range nearest {
+		if match.Offset < 0 {
+			ordered = append(ordered, match)
+		}
+	}
+	for _, match := range nearest {
+		if match.Offset >= 0 {
+			ordered = append(ordered, match)
+		}
+	}
+	return ordered
+}
@@ -5,68 +5,169 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 )
 
-// ParseYAMLDate extracts and parses the date from YAML frontmatter
+// dateFormats lists the date formats accepted in frontmatter
+var dateFormats = []string{
+	"2006-01-02",          // YYYY-MM-DD
+	"2006-01-02T15:04",    // ISO 8601 without seconds
+	"2006-01-02T15:04:05", // ISO 8601 without timezone
+	"2006-01-02 15:04:05", // YYYY-MM-DD HH:MM:SS
+	"01/02/2006",          // MM/DD/YYYY
+	"02/01/2006",          // DD/MM/YYYY
+	"January 2, 2006",     // Month DD, YYYY
+	"Jan 2, 2006",         // Mon DD, YYYY
+}
+
+// ParseDate parses a single frontmatter date value in any of the supported formats
+func ParseDate(value string) (time.Time, error) {
+	// Remove quotes if present
+	dateStr := strings.Trim(strings.TrimSpace(value), `"'`)
+
+	// Template files keep their placeholders until a note is created from them
+	if strings.Contains(dateStr, "{{") {
+		return time.Time{}, fmt.Errorf("unexpanded template placeholder: %s", dateStr)
+	}
+
+	// Try different date formats
+	for _, format := range dateFormats {
+		if date, err := time.Parse(format, dateStr); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
+
+// parseDateRangeValue parses a frontmatter date, or a FROM..TO range of dates
+func parseDateRangeValue(value string) (DateRange, error) {
+	fromStr, toStr, isRange := strings.Cut(strings.Trim(strings.TrimSpace(value), `"'`), "..")
+
+	start, err := ParseDate(fromStr)
+	if err != nil {
+		return DateRange{}, err
+	}
+	if !isRange {
+		return DateRange{Start: start, End: start}, nil
+	}
+
+	end, err := ParseDate(toStr)
+	if err != nil {
+		return DateRange{}, err
+	}
+	return NewDateRange(start, end)
+}
+
+// firstValue returns the first value of a frontmatter property, or ""
+func firstValue(properties map[string][]string, key string) string {
+	if values := properties[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseYAMLDate extracts and parses the date from YAML frontmatter. For notes
+// covering several days it returns the first day.
 func ParseYAMLDate(content string) (time.Time, error) {
+	dates, err := ParseYAMLDateRange(content)
+	return dates.Start, err
+}
+
+// ParseYAMLDateRange extracts the days a note covers from YAML frontmatter:
+// a date property, which may be a range like 2019-07-01..2019-07-14, or
+// start and end properties
+func ParseYAMLDateRange(content string) (DateRange, error) {
 	// Check if content starts with YAML frontmatter
 	if !strings.HasPrefix(content, "---") {
-		return time.Time{}, fmt.Errorf("no YAML frontmatter found")
+		return DateRange{}, fmt.Errorf("no YAML frontmatter found")
 	}
 
-	lines := strings.Split(content, "\n")
-	inFrontmatter := false
+	dates, found, err := propertyDateRange(ParseFrontmatter(content), PrimaryDateProperty)
+	if !found {
+		return DateRange{}, fmt.Errorf("no date property found in YAML frontmatter")
+	}
+	return dates, err
+}
 
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
+// propertyDateRange parses one date property. The date property falls back to
+// start, and a single date is extended into a range by an end property.
+func propertyDateRange(properties map[string][]string, key string) (DateRange, bool, error) {
+	value := firstValue(properties, key)
+	if value == "" && key == PrimaryDateProperty {
+		value = firstValue(properties, "start")
+	}
+	if value == "" {
+		return DateRange{}, false, nil
+	}
 
-		if i == 0 && line == "---" {
-			inFrontmatter = true
-			continue
-		}
+	dates, err := parseDateRangeValue(value)
+	if err != nil {
+		return DateRange{}, true, err
+	}
 
-		if inFrontmatter && line == "---" {
-			break
+	// An end property extends a single date into a range
+	if endValue := firstValue(properties, "end"); key == PrimaryDateProperty && endValue != "" && !dates.Multiday() {
+		end, err := ParseDate(endValue)
+		if err != nil {
+			return DateRange{}, true, err
 		}
+		dates, err = NewDateRange(dates.Start, end)
+		return dates, true, err
+	}
+
+	return dates, true, nil
+}
+
+// PrimaryDateProperty is the frontmatter property journal notes are dated by
+const PrimaryDateProperty = "date"
 
-		if inFrontmatter {
-			// Look for date property (supports various formats)
-			dateRegex := regexp.MustCompile(`^date\s*:\s*(.+)$`)
-			matches := dateRegex.FindStringSubmatch(line)
-
-			if len(matches) > 1 {
-				dateStr := strings.TrimSpace(matches[1])
-				// Remove quotes if present
-				dateStr = strings.Trim(dateStr, `"'`)
-
-				// Try different date formats
-				formats := []string{
-					"2006-01-02",          // YYYY-MM-DD
-					"2006-01-02T15:04",    // ISO 8601 without seconds
-					"2006-01-02T15:04:05", // ISO 8601 without timezone
-					"2006-01-02 15:04:05", // YYYY-MM-DD HH:MM:SS
-					"01/02/2006",          // MM/DD/YYYY
-					"02/01/2006",          // DD/MM/YYYY
-					"January 2, 2006",     // Month DD, YYYY
-					"Jan 2, 2006",         // Mon DD, YYYY
-					"{{date}}T{{time}}",   // Template format
-				}
-
-				for _, format := range formats {
-					if date, err := time.Parse(format, dateStr); err == nil {
-						return date, nil
-					}
-				}
-
-				return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+// DefaultDateProperties are the frontmatter properties read for dates when none are configured
+var DefaultDateProperties = []string{PrimaryDateProperty}
+
+// NoteDate is a date range read from one frontmatter property
+type NoteDate struct {
+	Property string
+	DateRange
+}
+
+// ParseYAMLDates extracts the dates of each of the given frontmatter
+// properties, in order. Properties the note lacks are skipped; it is an error
+// only if none of them holds a date.
+func ParseYAMLDates(content string, properties []string) ([]NoteDate, error) {
+	// Check if content starts with YAML frontmatter
+	if !strings.HasPrefix(content, "---") {
+		return nil, fmt.Errorf("no YAML frontmatter found")
+	}
+	if len(properties) == 0 {
+		properties = DefaultDateProperties
+	}
+
+	frontmatter := ParseFrontmatter(content)
+	var dates []NoteDate
+	var firstErr error
+	for _, property := range properties {
+		dateRange, found, err := propertyDateRange(frontmatter, property)
+		if !found {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %v", property, err)
 			}
+			continue
 		}
+		dates = append(dates, NoteDate{Property: property, DateRange: dateRange})
 	}
 
-	return time.Time{}, fmt.Errorf("no date property found in YAML frontmatter")
+	if len(dates) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("no %s property found in YAML frontmatter", strings.Join(properties, ", "))
+	}
+	return dates, nil
 }
 
 // ReadFileContent reads the entire content of a file
@@ -81,9 +182,48 @@ func ReadFileContent(filePath string) (string, error) {
 // DateMatcher is a function type that determines if a file date matches the criteria
 type DateMatcher func(fileDate, referenceDate time.Time) bool
 
-// ScanMarkdownNotes scans the specified folder for markdown notes matching the date criteria
-func ScanMarkdownNotes(folderPath string, matcher DateMatcher, referenceDate time.Time, verbose bool) ([]string, error) {
-	var matchingNotes []string
+// Note is a markdown file read during a vault scan
+type Note struct {
+	Path    string
+	Content string
+	Date    time.Time  // Zero when the note has no parseable date
+	End     time.Time  // Last day covered, equal to Date for single-day notes
+	Dates   []NoteDate // Every configured date property, the first giving Date and End
+	DateErr error      // Why the date could not be parsed, if it could not
+	Tags    []string   // Frontmatter and inline tags, normalized
+}
+
+// Dated reports whether the note has a date
+func (n Note) Dated() bool {
+	return n.DateErr == nil
+}
+
+// Range returns the days the note covers
+func (n Note) Range() DateRange {
+	return DateRange{Start: n.Date, End: n.End}
+}
+
+// Match is a note selected for a reference date
+type Match struct {
+	Note
+	Source NoteDate  // Date property that matched
+	Day    time.Time // Day that matched, within the property's range
+}
+
+// DayNumber returns which day of a multi-day range matched, starting from 1
+func (m Match) DayNumber() int {
+	return m.Source.DayNumber(m.Day)
+}
+
+// DayCount returns the number of days the matched range covers
+func (m Match) DayCount() int {
+	return m.Source.Days()
+}
+
+// ScanNotes reads every markdown note in the folder, dated or not, taking
+// dates from the given frontmatter properties (DefaultDateProperties if nil)
+func ScanNotes(folderPath string, dateProperties []string, verbose bool) ([]Note, error) {
+	var notes []Note
 
 	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -109,24 +249,83 @@ func ScanMarkdownNotes(folderPath string, matcher DateMatcher, referenceDate tim
 			return nil // Continue processing other files
 		}
 
-		// Parse date from YAML frontmatter
-		fileDate, err := ParseYAMLDate(content)
-		if err != nil {
-			if verbose {
-				fmt.Printf("Warning: Could not parse date from %s: %v\n", path, err)
-			}
-			return nil // Continue processing other files
+		// Parse dates from YAML frontmatter
+		dates, err := ParseYAMLDates(content, dateProperties)
+		note := Note{
+			Path:    path,
+			Content: content,
+			Dates:   dates,
+			DateErr: err,
+			Tags:    ParseTags(content),
 		}
-
-		// Check if the date matches using the provided matcher
-		if matcher(fileDate, referenceDate) {
-			matchingNotes = append(matchingNotes, path)
+		if len(dates) > 0 {
+			note.Date = dates[0].Start
+			note.End = dates[0].End
 		}
+		notes = append(notes, note)
 
 		return nil
 	})
 
-	return matchingNotes, err
+	return notes, err
+}
+
+// ScanDatedNotes returns the notes in the folder that have a date
+func ScanDatedNotes(folderPath string, dateProperties []string, verbose bool) ([]Note, error) {
+	notes, err := ScanNotes(folderPath, dateProperties, verbose)
+	if err != nil {
+		return nil, err
+	}
+	return DatedNotes(notes, verbose), nil
+}
+
+// DatedNotes returns the notes that have a date, warning about the others when verbose
+func DatedNotes(notes []Note, verbose bool) []Note {
+	var dated []Note
+	for _, note := range notes {
+		if !note.Dated() {
+			if verbose {
+				fmt.Printf("Warning: Could not parse date from %s: %v\n", note.Path, note.DateErr)
+			}
+			continue // Continue processing other files
+		}
+		dated = append(dated, note)
+	}
+	return dated
+}
+
+// ScanMarkdownNotes scans the specified folder for markdown notes matching the date criteria
+func ScanMarkdownNotes(folderPath string, matcher DateMatcher, referenceDate time.Time, verbose bool) ([]string, error) {
+	notes, err := ScanDatedNotes(folderPath, nil, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingNotes []string
+	for _, note := range SelectNotes(notes, matcher, referenceDate, TagFilter{}) {
+		matchingNotes = append(matchingNotes, note.Path)
+	}
+
+	return matchingNotes, nil
+}
+
+// SelectNotes returns the notes with a day matching the reference date and whose tags pass the filter.
+// Each date property is matched independently, in order, and notes covering several days match if
+// any of their days does.
+func SelectNotes(notes []Note, matcher DateMatcher, referenceDate time.Time, tags TagFilter) []Match {
+	var selected []Match
+	for _, note := range notes {
+		if !note.Dated() || !tags.Matches(note.Tags) {
+			continue
+		}
+		for _, date := range note.Dates {
+			if day, ok := date.Match(matcher, referenceDate); ok {
+				selected = append(selected, Match{Note: note, Source: date, Day: day})
+				break
+			}
+		}
+	}
+	return selected
 }
 
 // ExactDateMatcher returns true if the file date exactly matches the reference date (same year, month, day)
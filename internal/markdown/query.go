@@ -0,0 +1,266 @@
+package markdown
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMatchExpr is the match expression used when none is given
+const DefaultMatchExpr = "sameday"
+
+// ParseMatcher builds a DateMatcher from a match expression such as
+// "sameday or window:3d" or "samemonth and not yearsago:1".
+//
+// Terms:
+//
+//	sameday             same month and day, any year
+//	exact               same year, month and day
+//	sameweek            same ISO week number, any year
+//	samemonth           same month, any year
+//	weekdayofmonth      same weekday occurrence in the month, e.g. second Tuesday of October
+//	window:N[d|w]       within N days (or weeks) of the month and day, any year
+//	yearsago:N          exactly N years before
+//	range:FROM..TO      between two YYYY-MM-DD dates; either end may be left out
+//
+// Terms combine with and, or, not and parentheses; and binds tighter than or.
+// The leap day policy decides when sameday and yearsago match February 29 notes.
+func ParseMatcher(expr string, leapDay LeapDayPolicy) (DateMatcher, error) {
+	if strings.TrimSpace(expr) == "" {
+		expr = DefaultMatchExpr
+	}
+
+	p := &matchParser{tokens: tokenizeMatchExpr(expr), leapDay: leapDay}
+	matcher, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid match expression %q: %v", expr, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid match expression %q: unexpected %q", expr, p.tokens[p.pos])
+	}
+	return matcher, nil
+}
+
+// tokenizeMatchExpr splits an expression into words and parentheses
+func tokenizeMatchExpr(expr string) []string {
+	var tokens []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, c := range expr {
+		switch {
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// matchParser is a recursive descent parser over match expression tokens
+type matchParser struct {
+	tokens  []string
+	pos     int
+	leapDay LeapDayPolicy
+}
+
+// peek returns the next token, lowercased, without consuming it
+func (p *matchParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return strings.ToLower(p.tokens[p.pos])
+}
+
+func (p *matchParser) parseOr() (DateMatcher, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	matchers := []DateMatcher{first}
+	for p.peek() == "or" {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, next)
+	}
+	if len(matchers) == 1 {
+		return first, nil
+	}
+	return Or(matchers...), nil
+}
+
+func (p *matchParser) parseAnd() (DateMatcher, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	matchers := []DateMatcher{first}
+	for p.peek() == "and" {
+		p.pos++
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, next)
+	}
+	if len(matchers) == 1 {
+		return first, nil
+	}
+	return And(matchers...), nil
+}
+
+func (p *matchParser) parseUnary() (DateMatcher, error) {
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "not":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(inner), nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "and", "or":
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	term := p.tokens[p.pos]
+	p.pos++
+	return p.parseTerm(term)
+}
+
+// parseTerm builds the matcher for a single term such as window:3d
+func (p *matchParser) parseTerm(term string) (DateMatcher, error) {
+	name, arg, hasArg := strings.Cut(term, ":")
+	name = strings.ToLower(name)
+
+	requireArg := func() error {
+		if !hasArg || arg == "" {
+			return fmt.Errorf("%s needs an argument, e.g. %s", name, matchTermExamples[name])
+		}
+		return nil
+	}
+	noArg := func() error {
+		if hasArg {
+			return fmt.Errorf("%s takes no argument", name)
+		}
+		return nil
+	}
+
+	switch name {
+	case "sameday":
+		return SameDayMatcherFor(p.leapDay), noArg()
+	case "exact":
+		return ExactDateMatcher, noArg()
+	case "sameweek":
+		return SameWeekMatcher, noArg()
+	case "samemonth":
+		return SameMonthMatcher, noArg()
+	case "weekdayofmonth":
+		return WeekdayOfMonthMatcher, noArg()
+	case "window":
+		if err := requireArg(); err != nil {
+			return nil, err
+		}
+		days, err := ParseDays(arg)
+		if err != nil {
+			return nil, err
+		}
+		return WindowMatcher(days), nil
+	case "yearsago":
+		if err := requireArg(); err != nil {
+			return nil, err
+		}
+		years, err := strconv.Atoi(arg)
+		if err != nil || years < 0 {
+			return nil, fmt.Errorf("invalid number of years: %s", arg)
+		}
+		return YearsAgoMatcher(years, p.leapDay), nil
+	case "range":
+		if err := requireArg(); err != nil {
+			return nil, err
+		}
+		from, to, err := parseDateRangeArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		return RangeMatcher(from, to), nil
+	}
+
+	return nil, fmt.Errorf("unknown matcher %q", name)
+}
+
+// matchTermExamples shows the argument syntax in error messages
+var matchTermExamples = map[string]string{
+	"window":   "window:3d",
+	"yearsago": "yearsago:5",
+	"range":    "range:2019-01-01..2019-12-31",
+}
+
+// ParseDays parses a span such as 3, 3d or 2w into days
+func ParseDays(value string) (int, error) {
+	multiplier := 1
+	number := value
+	switch {
+	case strings.HasSuffix(value, "d"):
+		number = strings.TrimSuffix(value, "d")
+	case strings.HasSuffix(value, "w"):
+		number = strings.TrimSuffix(value, "w")
+		multiplier = 7
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid span: %s", value)
+	}
+	return n * multiplier, nil
+}
+
+// parseDateRangeArg parses FROM..TO where either date may be omitted
+func parseDateRangeArg(value string) (time.Time, time.Time, error) {
+	fromStr, toStr, ok := strings.Cut(value, "..")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range %q, expected FROM..TO", value)
+	}
+
+	var from, to time.Time
+	var err error
+	if fromStr != "" {
+		if from, err = time.Parse("2006-01-02", fromStr); err != nil {
+			return from, to, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", fromStr)
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse("2006-01-02", toStr); err != nil {
+			return from, to, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", toStr)
+		}
+	}
+	return from, to, nil
+}
@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMatcher(t *testing.T) {
+	// A note from four years before the reference date, on the same day: the
+	// expressions are built so that a wrong precedence gives the other answer
+	note, reference := date(2020, time.March, 10), date(2024, time.March, 10)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"   ", true},
+		{"sameday", true},
+		{"exact", false},
+		{"samemonth", true},
+		{"sameweek", false},
+		{"weekdayofmonth", false},
+		{"window:3d", true},
+		{"window:1w", true},
+		{"yearsago:4", true},
+		{"yearsago:1", false},
+		{"range:2020-01-01..2020-12-31", true},
+		{"range:..2019-12-31", false},
+		{"range:2021-01-01..", false},
+		{"SameDay OR Exact", true},
+
+		// and binds tighter than or
+		{"exact and sameday or samemonth", true},
+		{"samemonth or exact and yearsago:1", true},
+		{"exact and (sameday or samemonth)", false},
+		{"(samemonth or exact) and yearsago:1", false},
+
+		// not binds tighter than and
+		{"not sameday and exact", false},
+		{"not (sameday and exact)", true},
+		{"not exact and sameday", true},
+		{"not not sameday", true},
+		{"not (exact or sameday)", false},
+
+		{"(sameday)", true},
+		{"((exact) or (yearsago:4))", true},
+		{"(samemonth)and(not exact)", true},
+	}
+	for _, tt := range tests {
+		matcher, err := ParseMatcher(tt.expr, DefaultLeapDayPolicy)
+		if err != nil {
+			t.Errorf("ParseMatcher(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := matcher(note, reference); got != tt.want {
+			t.Errorf("ParseMatcher(%q) matched = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseMatcherErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// Arguments
+		{"window", `invalid match expression "window": window needs an argument, e.g. window:3d`},
+		{"window:", `invalid match expression "window:": window needs an argument, e.g. window:3d`},
+		{"window:soon", `invalid match expression "window:soon": invalid span: soon`},
+		{"window:-2d", `invalid match expression "window:-2d": invalid span: -2d`},
+		{"yearsago", `invalid match expression "yearsago": yearsago needs an argument, e.g. yearsago:5`},
+		{"yearsago:two", `invalid match expression "yearsago:two": invalid number of years: two`},
+		{"yearsago:-1", `invalid match expression "yearsago:-1": invalid number of years: -1`},
+		{"range", `invalid match expression "range": range needs an argument, e.g. range:2019-01-01..2019-12-31`},
+		{"range:2020-01-01", `invalid match expression "range:2020-01-01": invalid range "2020-01-01", expected FROM..TO`},
+		{"range:2020-13-01..", `invalid match expression "range:2020-13-01..": invalid date "2020-13-01", expected YYYY-MM-DD`},
+		{"range:..2020/12/31", `invalid match expression "range:..2020/12/31": invalid date "2020/12/31", expected YYYY-MM-DD`},
+		{"sameday:3", `invalid match expression "sameday:3": sameday takes no argument`},
+
+		// Unknown identifiers
+		{"someday", `invalid match expression "someday": unknown matcher "someday"`},
+		{"Sameday or Someday", `invalid match expression "Sameday or Someday": unknown matcher "someday"`},
+		{"sameday and nor exact", `invalid match expression "sameday and nor exact": unknown matcher "nor"`},
+
+		// Syntax
+		{"not", `invalid match expression "not": unexpected end of expression`},
+		{"sameday and", `invalid match expression "sameday and": unexpected end of expression`},
+		{"()", `invalid match expression "()": unexpected ")"`},
+		{"(sameday", `invalid match expression "(sameday": missing closing parenthesis`},
+		{"sameday)", `invalid match expression "sameday)": unexpected ")"`},
+		{"and sameday", `invalid match expression "and sameday": unexpected "and"`},
+		{"sameday or OR exact", `invalid match expression "sameday or OR exact": unexpected "OR"`},
+		{"sameday samemonth", `invalid match expression "sameday samemonth": unexpected "samemonth"`},
+	}
+	for _, tt := range tests {
+		matcher, err := ParseMatcher(tt.expr, DefaultLeapDayPolicy)
+		if err == nil {
+			t.Errorf("ParseMatcher(%q) succeeded, want error %q", tt.expr, tt.want)
+			continue
+		}
+		if err.Error() != tt.want || matcher != nil {
+			t.Errorf("ParseMatcher(%q) error = %q, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package markdown
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RandomOptions limits and weights the notes PickRandom chooses from
+type RandomOptions struct {
+	Tags        TagFilter
+	Years       []int // Years the note may be dated in; empty allows any
+	WeightOlder bool  // Favour older notes in proportion to their age
+}
+
+// inYears reports whether a note covers any of the years, or years is empty
+func inYears(note Note, years []int) bool {
+	if len(years) == 0 {
+		return true
+	}
+	for _, year := range years {
+		if year >= note.Date.Year() && year <= note.End.Year() {
+			return true
+		}
+	}
+	return false
+}
+
+// PickRandom returns a random dated note passing the options, or false if
+// there is none. Notes dated after the reference date are never picked.
+func PickRandom(notes []Note, opts RandomOptions, referenceDate time.Time) (Note, bool) {
+	var candidates []Note
+	var weights []float64
+	total := 0.0
+
+	for _, note := range notes {
+		if !note.Dated() || note.Date.After(referenceDate) || !opts.Tags.Matches(note.Tags) || !inYears(note, opts.Years) {
+			continue
+		}
+		weight := 1.0
+		if opts.WeightOlder {
+			// One more than the age in days, so today's notes can still come up
+			weight += float64(daysBetween(note.Date, referenceDate))
+		}
+		candidates = append(candidates, note)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if len(candidates) == 0 {
+		return Note{}, false
+	}
+
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return candidates[i], true
+		}
+		r -= weight
+	}
+	return candidates[len(candidates)-1], true
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Heading returns the text of a markdown heading line such as "## Trip notes"
+func Heading(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	heading := strings.TrimLeft(line, "#")
+	if heading == line || !strings.HasPrefix(heading, " ") {
+		return "", false
+	}
+	return strings.TrimSpace(heading), true
+}
+
+// ContentTitle returns the note's frontmatter title, else a heading on its
+// first line, else ""
+func ContentTitle(content string) string {
+	if title := firstValue(ParseFrontmatter(content), "title"); title != "" {
+		return title
+	}
+	for _, line := range strings.Split(Body(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		heading, _ := Heading(line)
+		return heading
+	}
+	return ""
+}
+
+// Title returns the note's title: its frontmatter title, else a heading on
+// its first line, else the file name without .md
+func Title(path, content string) string {
+	if title := ContentTitle(content); title != "" {
+		return title
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Excerpt returns the opening of a note's body as plain text, cut at a word
+// to at most length bytes
+func Excerpt(content string, length int) string {
+	var lines []string
+	for _, line := range strings.Split(Body(content), "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#>-*+ "))
+		if line == "" || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "[ ]") || strings.HasPrefix(line, "[x]") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	text := strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	if len(text) <= length {
+		return text
+	}
+	cut := strings.LastIndex(text[:length], " ")
+	if cut <= 0 {
+		// A single long word is cut mid-word, but never mid-character
+		cut = length
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return text[:cut] + "…"
+}
@@ -0,0 +1,143 @@
+package markdown
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hashtagRegex matches an inline #tag. Obsidian tags may contain letters,
+// numbers, underscores, hyphens and slashes for nesting.
+var hashtagRegex = regexp.MustCompile(`(?:^|[\s(\[,;])#([\p{L}\p{N}_/-]+)`)
+
+// inlineCodeRegex matches `inline code` spans, which never contain tags
+var inlineCodeRegex = regexp.MustCompile("`[^`]*`")
+
+// NormalizeTag lowercases a tag and strips its leading #
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// isValidTag reports whether tag is usable: Obsidian requires at least one non-numeric character
+func isValidTag(tag string) bool {
+	tag = strings.Trim(tag, "/")
+	if tag == "" {
+		return false
+	}
+	for _, c := range tag {
+		if c < '0' || c > '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags returns the note's tags from the frontmatter tags property and
+// inline #hashtags in the body, normalized, deduplicated and sorted
+func ParseTags(content string) []string {
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = NormalizeTag(tag)
+		if isValidTag(tag) {
+			seen[tag] = true
+		}
+	}
+
+	properties := ParseFrontmatter(content)
+	for _, key := range []string{"tags", "tag"} {
+		for _, value := range properties[key] {
+			// A scalar may hold several tags separated by commas or spaces
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+				add(tag)
+			}
+		}
+	}
+
+	body, _ := stripFrontmatter(content)
+	inCodeBlock := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		line = inlineCodeRegex.ReplaceAllString(line, "")
+		for _, match := range hashtagRegex.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// stripFrontmatter returns the note body after its frontmatter and the number of lines removed
+func stripFrontmatter(content string) (string, int) {
+	if !strings.HasPrefix(content, "---") {
+		return content, 0
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[i+1:], "\n"), i + 1
+		}
+	}
+	return content, 0
+}
+
+// Body returns the note content after its frontmatter
+func Body(content string) string {
+	body, _ := stripFrontmatter(content)
+	return body
+}
+
+// HasTag reports whether tags contains tag, counting nested tags: a note
+// tagged family/kids has the tag family
+func HasTag(tags []string, tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range tags {
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// TagFilter limits notes by tag. A note passes if it has any of the included
+// tags (or Include is empty) and none of the excluded tags.
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Empty reports whether the filter lets every note through
+func (f TagFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Matches reports whether a note with the given tags passes the filter
+func (f TagFilter) Matches(tags []string) bool {
+	for _, tag := range f.Exclude {
+		if HasTag(tags, tag) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, tag := range f.Include {
+		if HasTag(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+package markdown
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/filelock"
+)
+
+// ErrContentChanged is returned when a file no longer matches the version a change was based on
+var ErrContentChanged = errors.New("file changed since it was read")
+
+// taskLineRegex matches a Markdown task list item: - [ ] text, * [x] text or + [X] text
+var taskLineRegex = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\].*)$`)
+
+// taskItemRegex matches any task list item, including custom statuses such as [-] or [/]
+var taskItemRegex = regexp.MustCompile(`^(\s*)[-*+]\s+\[(.)\]\s+(.*)$`)
+
+// taskDateRegex matches an Obsidian Tasks date field such as 📅 2024-03-01
+var taskDateRegex = regexp.MustCompile(`(📅|⏳|🛫|✅|➕|❌)\x{FE0F}?\s*(\d{4}-\d{2}-\d{2})`)
+
+// Task priorities, as written by the Obsidian Tasks plugin
+const (
+	PriorityNone    = ""
+	PriorityHighest = "highest"
+	PriorityHigh    = "high"
+	PriorityMedium  = "medium"
+	PriorityLow     = "low"
+	PriorityLowest  = "lowest"
+)
+
+// priorityEmoji lists Obsidian Tasks priority markers, highest first
+var priorityEmoji = []struct {
+	Emoji    string
+	Priority string
+}{
+	{"🔺", PriorityHighest},
+	{"⏫", PriorityHigh},
+	{"🔼", PriorityMedium},
+	{"🔽", PriorityLow},
+	{"⏬", PriorityLowest},
+}
+
+// Task is a task list item, with any Obsidian Tasks metadata parsed out of its text
+type Task struct {
+	Path      string    // Note the task was found in (set by CollectTasks)
+	NoteDate  time.Time // Date of that note (set by CollectTasks)
+	Line      int       // Zero-based line within the note
+	Text      string    // Description with metadata removed
+	Status    rune      // Character between the brackets
+	Priority  string
+	Due       time.Time
+	Scheduled time.Time
+	Start     time.Time
+	Created   time.Time
+	DoneDate  time.Time
+}
+
+// Done reports whether the task is checked off
+func (t Task) Done() bool {
+	return t.Status == 'x' || t.Status == 'X'
+}
+
+// Cancelled reports whether the task was marked [-]
+func (t Task) Cancelled() bool {
+	return t.Status == '-'
+}
+
+// Open reports whether the task still needs doing
+func (t Task) Open() bool {
+	return !t.Done() && !t.Cancelled()
+}
+
+// Overdue reports whether the task is open and its due date is before the reference day
+func (t Task) Overdue(referenceDate time.Time) bool {
+	if !t.Open() || t.Due.IsZero() {
+		return false
+	}
+	today := time.Date(referenceDate.Year(), referenceDate.Month(), referenceDate.Day(), 0, 0, 0, 0, time.UTC)
+	return t.Due.Before(today)
+}
+
+// ParseTaskLine parses a single line as a task list item
+func ParseTaskLine(line string) (Task, bool) {
+	matches := taskItemRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+	if matches == nil {
+		return Task{}, false
+	}
+
+	task := Task{Status: []rune(matches[2])[0]}
+	text := matches[3]
+
+	for _, field := range taskDateRegex.FindAllStringSubmatch(text, -1) {
+		date, err := time.Parse("2006-01-02", field[2])
+		if err != nil {
+			continue
+		}
+		switch field[1] {
+		case "📅":
+			task.Due = date
+		case "⏳":
+			task.Scheduled = date
+		case "🛫":
+			task.Start = date
+		case "➕":
+			task.Created = date
+		case "✅", "❌":
+			task.DoneDate = date
+		}
+	}
+	text = taskDateRegex.ReplaceAllString(text, "")
+
+	// A task marked with several priorities takes the highest
+	for _, marker := range priorityEmoji {
+		if strings.Contains(text, marker.Emoji) {
+			if task.Priority == "" {
+				task.Priority = marker.Priority
+			}
+			text = strings.ReplaceAll(text, marker.Emoji, "")
+		}
+	}
+
+	task.Text = strings.Join(strings.Fields(strings.ReplaceAll(text, "\uFE0F", "")), " ")
+	return task, true
+}
+
+// ExtractTasks returns the task list items in markdown content, skipping fenced code blocks
+func ExtractTasks(content string) []Task {
+	var tasks []Task
+	inCodeBlock := false
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+
+		if task, ok := ParseTaskLine(line); ok {
+			task.Line = i
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+// Task filters accepted by FilterTasks
+const (
+	TaskFilterAll     = "all"
+	TaskFilterOpen    = "open"
+	TaskFilterDone    = "done"
+	TaskFilterOverdue = "overdue"
+)
+
+// CollectTasks gathers the tasks of every dated note, newest note first
+func CollectTasks(notes []Note) []Task {
+	var tasks []Task
+	for _, note := range notes {
+		if !note.Dated() {
+			continue
+		}
+		for _, task := range ExtractTasks(note.Content) {
+			task.Path = note.Path
+			task.NoteDate = note.Date
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].NoteDate.Equal(tasks[j].NoteDate) {
+			return tasks[i].NoteDate.After(tasks[j].NoteDate)
+		}
+		if tasks[i].Path != tasks[j].Path {
+			return tasks[i].Path < tasks[j].Path
+		}
+		return tasks[i].Line < tasks[j].Line
+	})
+	return tasks
+}
+
+// FilterTasks keeps the tasks matching filter (open, done, overdue or all)
+func FilterTasks(tasks []Task, filter string, referenceDate time.Time) ([]Task, error) {
+	var keep func(Task) bool
+	switch filter {
+	case TaskFilterAll, "":
+		return tasks, nil
+	case TaskFilterOpen:
+		keep = Task.Open
+	case TaskFilterDone:
+		keep = Task.Done
+	case TaskFilterOverdue:
+		keep = func(t Task) bool { return t.Overdue(referenceDate) }
+	default:
+		return nil, fmt.Errorf("unknown task filter %q (want open, done, overdue or all)", filter)
+	}
+
+	var filtered []Task
+	for _, task := range tasks {
+		if keep(task) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered, nil
+}
+
+// ContentHash returns a fingerprint of file content used for optimistic concurrency checks
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ToggleTaskLine flips the checkbox of a task list line
+func ToggleTaskLine(line string) (string, bool, error) {
+	matches := taskLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return line, false, fmt.Errorf("not a task: %s", line)
+	}
+
+	checked := matches[2] == " "
+	mark := " "
+	if checked {
+		mark = "x"
+	}
+	return matches[1] + mark + matches[3], checked, nil
+}
+
+// ToggleTask flips the task on the given zero-based line of a file. The change
+// is only written if the file still hashes to expectedHash, so edits made
+// elsewhere since the caller read the file are not lost. It returns the new
+// hash and whether the task is now checked.
+func ToggleTask(path string, line int, expectedHash string) (string, bool, error) {
+	// Hold the lock appends take, so an entry added between the read and the
+	// rename is not lost
+	unlock, err := filelock.Lock(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer unlock()
+
+	content, err := ReadFileContent(path)
+	if err != nil {
+		return "", false, err
+	}
+	if ContentHash(content) != expectedHash {
+		return "", false, ErrContentChanged
+	}
+
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false, fmt.Errorf("line %d out of range", line)
+	}
+
+	toggled, checked, err := ToggleTaskLine(lines[line])
+	if err != nil {
+		return "", false, err
+	}
+	lines[line] = toggled
+	updated := strings.Join(lines, "\n")
+
+	if err := writeFileAtomic(path, updated); err != nil {
+		return "", false, err
+	}
+	return ContentHash(updated), checked, nil
+}
+
+// writeFileAtomic replaces a file's content via a temporary file and rename,
+// so readers such as Obsidian never see a partially written note
+func writeFileAtomic(path, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
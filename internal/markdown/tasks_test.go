@@ -0,0 +1,116 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTaskLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Task
+		ok   bool
+	}{
+		{"- [ ] Post letter", Task{Status: ' ', Text: "Post letter"}, true},
+		{"  * [x] Call Ana", Task{Status: 'x', Text: "Call Ana"}, true},
+		{"+ [-] Cancelled trip\r", Task{Status: '-', Text: "Cancelled trip"}, true},
+		{"- [ ]", Task{}, false},
+		{"- [] Not a task", Task{}, false},
+		{"Post letter", Task{}, false},
+
+		// Dates
+		{
+			"- [ ] Renew passport 📅 2024-03-01",
+			Task{Status: ' ', Text: "Renew passport", Due: date(2024, time.March, 1)},
+			true,
+		},
+		{
+			"- [ ] Renew passport ➕ 2024-01-02 🛫 2024-02-01 ⏳ 2024-02-15 📅 2024-03-01",
+			Task{Status: ' ', Text: "Renew passport", Created: date(2024, time.January, 2), Start: date(2024, time.February, 1), Scheduled: date(2024, time.February, 15), Due: date(2024, time.March, 1)},
+			true,
+		},
+		{
+			"- [x] Renew passport 📅 2024-03-01 ✅ 2024-02-28",
+			Task{Status: 'x', Text: "Renew passport", Due: date(2024, time.March, 1), DoneDate: date(2024, time.February, 28)},
+			true,
+		},
+		{
+			"- [-] Renew passport ❌ 2024-02-28",
+			Task{Status: '-', Text: "Renew passport", DoneDate: date(2024, time.February, 28)},
+			true,
+		},
+		{
+			"- [ ] Emoji with a variation selector 📅️ 2024-03-01",
+			Task{Status: ' ', Text: "Emoji with a variation selector", Due: date(2024, time.March, 1)},
+			true,
+		},
+		{
+			"- [ ] Date without space 📅2024-03-01",
+			Task{Status: ' ', Text: "Date without space", Due: date(2024, time.March, 1)},
+			true,
+		},
+		{
+			"- [ ] Invalid date 📅 2024-02-30",
+			Task{Status: ' ', Text: "Invalid date"},
+			true,
+		},
+		{
+			"- [ ] Undated 📅 soon",
+			Task{Status: ' ', Text: "Undated 📅 soon"},
+			true,
+		},
+
+		// Priorities
+		{"- [ ] Highest 🔺", Task{Status: ' ', Text: "Highest", Priority: PriorityHighest}, true},
+		{"- [ ] High ⏫", Task{Status: ' ', Text: "High", Priority: PriorityHigh}, true},
+		{"- [ ] Medium 🔼", Task{Status: ' ', Text: "Medium", Priority: PriorityMedium}, true},
+		{"- [ ] Low 🔽", Task{Status: ' ', Text: "Low", Priority: PriorityLow}, true},
+		{"- [ ] Lowest ⏬", Task{Status: ' ', Text: "Lowest", Priority: PriorityLowest}, true},
+		{"- [ ] Low then high 🔽 ⏫", Task{Status: ' ', Text: "Low then high", Priority: PriorityHigh}, true},
+		{"- [ ] Lowest then highest ⏬ 🔼 🔺", Task{Status: ' ', Text: "Lowest then highest", Priority: PriorityHighest}, true},
+		{
+			"- [ ] Priority among dates 📅 2024-03-01 🔼 ⏳ 2024-02-15",
+			Task{Status: ' ', Text: "Priority among dates", Priority: PriorityMedium, Due: date(2024, time.March, 1), Scheduled: date(2024, time.February, 15)},
+			true,
+		},
+
+		// Recurrence rules are not parsed, so they stay in the text
+		{
+			"- [ ] Water plants 🔁 every week 📅 2024-03-01",
+			Task{Status: ' ', Text: "Water plants 🔁 every week", Due: date(2024, time.March, 1)},
+			true,
+		},
+		{
+			"- [ ] Pay rent ⏫ 🔁 every month on the 1st when done ⏳ 2024-03-01",
+			Task{Status: ' ', Text: "Pay rent 🔁 every month on the 1st when done", Priority: PriorityHigh, Scheduled: date(2024, time.March, 1)},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTaskLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseTaskLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTaskOverdue(t *testing.T) {
+	today := time.Date(2024, time.March, 1, 18, 0, 0, 0, time.Local)
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"- [ ] Due yesterday 📅 2024-02-29", true},
+		{"- [ ] Due today 📅 2024-03-01", false},
+		{"- [x] Done late 📅 2024-02-29", false},
+		{"- [-] Cancelled 📅 2024-02-29", false},
+		{"- [/] In progress 📅 2024-02-29", true},
+		{"- [ ] No due date ⏳ 2024-02-29", false},
+	}
+	for _, tt := range tests {
+		task, _ := ParseTaskLine(tt.line)
+		if got := task.Overdue(today); got != tt.want {
+			t.Errorf("ParseTaskLine(%q).Overdue() = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
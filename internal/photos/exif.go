@@ -0,0 +1,458 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EXIF tags read from the TIFF structure
+const (
+	tagOrientation       = 0x0112 // IFD0: how the image is rotated or flipped
+	tagDateTime          = 0x0132 // IFD0: when the file was last changed
+	tagExifIFD           = 0x8769 // IFD0: offset of the Exif IFD
+	tagDateTimeOriginal  = 0x9003 // Exif IFD: when the photo was taken
+	tagDateTimeDigitized = 0x9004 // Exif IFD: when the photo was stored digitally
+	tagThumbnailOffset   = 0x0201 // IFD1: offset of the JPEG thumbnail
+	tagThumbnailLength   = 0x0202 // IFD1: length of the JPEG thumbnail
+)
+
+// exifDateFormat is how EXIF records dates, in the camera's local time
+const exifDateFormat = "2006:01:02 15:04:05"
+
+// maxSegmentSize bounds how much of a file is read while looking for EXIF data
+const maxSegmentSize = 1 << 20
+
+// exifHeader starts the EXIF data in a JPEG APP1 segment and a HEIC Exif item
+var exifHeader = []byte("Exif\x00\x00")
+
+// exifData is what Salthaven reads from a photo's EXIF data
+type exifData struct {
+	Taken       time.Time // Zero when no date was recorded
+	Orientation int       // 1 to 8 as in EXIF, 1 being upright
+	Thumbnail   []byte    // Embedded JPEG thumbnail, if any
+}
+
+// readEXIF reads the EXIF data of a JPEG, PNG or HEIC file
+func readEXIF(path string) (exifData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return exifData{}, err
+	}
+	defer file.Close()
+
+	var tiff []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		tiff, err = jpegEXIF(file)
+	case ".png":
+		tiff, err = pngEXIF(file)
+	case ".heic", ".heif":
+		tiff, err = heicEXIF(file)
+	default:
+		return exifData{}, fmt.Errorf("unsupported image type")
+	}
+	if err != nil {
+		return exifData{}, err
+	}
+	if tiff == nil {
+		return exifData{}, fmt.Errorf("no EXIF data")
+	}
+	return parseTIFF(tiff)
+}
+
+// jpegEXIF returns the TIFF data of a JPEG's APP1 Exif segment
+func jpegEXIF(r io.Reader) ([]byte, error) {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return nil, fmt.Errorf("not a JPEG file")
+	}
+
+	for {
+		var marker [4]byte
+		if _, err := io.ReadFull(r, marker[:]); err != nil {
+			return nil, nil
+		}
+		if marker[0] != 0xFF {
+			return nil, fmt.Errorf("invalid JPEG segment")
+		}
+		// The image data starts at SOS; metadata always comes before it
+		if marker[1] == 0xDA || marker[1] == 0xD9 {
+			return nil, nil
+		}
+		length := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if length < 0 {
+			return nil, fmt.Errorf("invalid JPEG segment")
+		}
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil, err
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment[len(exifHeader):], nil
+		}
+	}
+}
+
+// pngEXIF returns the data of a PNG's eXIf chunk, which holds TIFF data directly
+func pngEXIF(r io.ReadSeeker) ([]byte, error) {
+	var signature [8]byte
+	if _, err := io.ReadFull(r, signature[:]); err != nil || string(signature[:]) != "\x89PNG\r\n\x1a\n" {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, nil
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		switch string(header[4:]) {
+		case "eXIf":
+			if length > maxSegmentSize {
+				return nil, fmt.Errorf("EXIF chunk too large")
+			}
+			data := make([]byte, length)
+			_, err := io.ReadFull(r, data)
+			return data, err
+		case "IEND":
+			return nil, nil
+		}
+		// Skip the chunk's data and CRC
+		if _, err := r.Seek(length+4, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// box is an ISO base media file box: its type and contents
+type box struct {
+	Type string
+	Data []byte
+}
+
+// readBoxes splits data into the boxes it contains
+func readBoxes(data []byte) []box {
+	var boxes []box
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		header := uint64(8)
+		if size == 1 && len(data) >= 16 {
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		} else if size == 0 {
+			size = uint64(len(data))
+		}
+		if size < header || size > uint64(len(data)) {
+			break
+		}
+		boxes = append(boxes, box{Type: string(data[4:8]), Data: data[header:size]})
+		data = data[size:]
+	}
+	return boxes
+}
+
+// readUint reads a big-endian unsigned integer of 0, 4 or 8 bytes, as used by iloc
+func readUint(data []byte, size int) (uint64, []byte, bool) {
+	if len(data) < size {
+		return 0, nil, false
+	}
+	switch size {
+	case 0:
+		return 0, data, true
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], true
+	case 8:
+		return binary.BigEndian.Uint64(data), data[8:], true
+	}
+	return 0, nil, false
+}
+
+// heicEXIF returns the TIFF data of a HEIC's Exif item. The item is found by
+// its type in the meta box's item info (iinf), then read from the file at the
+// place its item location (iloc) gives.
+func heicEXIF(r io.ReadSeeker) ([]byte, error) {
+	// The meta box comes before the image data, near the start of the file
+	head := make([]byte, maxSegmentSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	var meta []byte
+	for _, b := range readBoxes(head[:n]) {
+		if b.Type == "meta" && len(b.Data) >= 4 {
+			meta = b.Data[4:] // Skip the version and flags
+		}
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("no meta box")
+	}
+
+	// Find the Exif item's ID
+	exifID := uint32(0)
+	for _, b := range readBoxes(meta) {
+		if b.Type != "iinf" || len(b.Data) < 4 {
+			continue
+		}
+		// The version and flags, then a 2-byte entry count, or 4-byte from version 1
+		header := 6
+		if b.Data[0] > 0 {
+			header = 8
+		}
+		if len(b.Data) < header {
+			continue
+		}
+		for _, infe := range readBoxes(b.Data[header:]) {
+			data := infe.Data
+			if infe.Type != "infe" || len(data) < 4 || data[0] < 2 {
+				continue
+			}
+			var id uint32
+			var itemType string
+			if data[0] == 2 && len(data) >= 12 {
+				id, itemType = uint32(binary.BigEndian.Uint16(data[4:])), string(data[8:12])
+			} else if data[0] >= 3 && len(data) >= 14 {
+				id, itemType = binary.BigEndian.Uint32(data[4:]), string(data[10:14])
+			}
+			if itemType == "Exif" {
+				exifID = id
+			}
+		}
+	}
+	if exifID == 0 {
+		return nil, nil
+	}
+
+	// Find where the item is stored
+	for _, b := range readBoxes(meta) {
+		if b.Type != "iloc" || len(b.Data) < 6 {
+			continue
+		}
+		version := b.Data[0]
+		// The version and flags, the field sizes, then a 2-byte item count, or 4-byte from version 2
+		header := 8
+		if version >= 2 {
+			header = 10
+		}
+		if len(b.Data) < header {
+			return nil, fmt.Errorf("invalid iloc box")
+		}
+		offsetSize, lengthSize := int(b.Data[4]>>4), int(b.Data[4]&0x0F)
+		baseOffsetSize, indexSize := int(b.Data[5]>>4), 0
+		if version == 1 || version == 2 {
+			indexSize = int(b.Data[5] & 0x0F)
+		}
+		data := b.Data[6:]
+		var count uint32
+		if version < 2 {
+			count = uint32(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		} else {
+			count = binary.BigEndian.Uint32(data)
+			data = data[4:]
+		}
+
+		for i := uint32(0); i < count; i++ {
+			var id uint32
+			if version < 2 {
+				if len(data) < 2 {
+					return nil, fmt.Errorf("invalid iloc box")
+				}
+				id, data = uint32(binary.BigEndian.Uint16(data)), data[2:]
+			} else {
+				if len(data) < 4 {
+					return nil, fmt.Errorf("invalid iloc box")
+				}
+				id, data = binary.BigEndian.Uint32(data), data[4:]
+			}
+			if version == 1 || version == 2 {
+				data = data[min(2, len(data)):] // Construction method
+			}
+			data = data[min(2, len(data)):] // Data reference index
+
+			baseOffset, data, ok := readUint(data, baseOffsetSize)
+			if !ok || len(data) < 2 {
+				return nil, fmt.Errorf("invalid iloc box")
+			}
+			extents := int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+			var offset, length uint64
+			for e := 0; e < extents; e++ {
+				if _, data, ok = readUint(data, indexSize); !ok {
+					return nil, fmt.Errorf("invalid iloc box")
+				}
+				extentOffset, rest, ok1 := readUint(data, offsetSize)
+				extentLength, rest, ok2 := readUint(rest, lengthSize)
+				if !ok1 || !ok2 {
+					return nil, fmt.Errorf("invalid iloc box")
+				}
+				data = rest
+				if e == 0 {
+					offset, length = baseOffset+extentOffset, extentLength
+				}
+			}
+			if id == exifID {
+				return readHEICItem(r, offset, length)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no location for the Exif item")
+}
+
+// readHEICItem reads an Exif item: a 4-byte offset to the TIFF header, then the TIFF data
+func readHEICItem(r io.ReadSeeker, offset, length uint64) ([]byte, error) {
+	if length < 4 || length > maxSegmentSize {
+		return nil, fmt.Errorf("invalid Exif item")
+	}
+	if _, err := r.Seek(int64(offset), io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	skip := uint64(binary.BigEndian.Uint32(data)) + 4
+	if skip > length {
+		return nil, fmt.Errorf("invalid Exif item")
+	}
+	return data[skip:], nil
+}
+
+// tiffReader reads values from TIFF data in its byte order
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ifdEntry is a tag of an image file directory, with its value or the offset of its value
+type ifdEntry struct {
+	Tag   uint16
+	Type  uint16
+	Count uint32
+	Value []byte // The 4 bytes holding the value or its offset
+}
+
+// ifd reads the entries of the directory at offset, and the offset of the next directory
+func (t tiffReader) ifd(offset uint32) ([]ifdEntry, uint32, bool) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil, 0, false
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+	start := int(offset) + 2
+	if start+count*12+4 > len(t.data) {
+		return nil, 0, false
+	}
+	entries := make([]ifdEntry, count)
+	for i := range entries {
+		e := t.data[start+i*12:]
+		entries[i] = ifdEntry{
+			Tag:   t.order.Uint16(e),
+			Type:  t.order.Uint16(e[2:]),
+			Count: t.order.Uint32(e[4:]),
+			Value: e[8:12],
+		}
+	}
+	return entries, t.order.Uint32(t.data[start+count*12:]), true
+}
+
+// uint reads a SHORT or LONG value
+func (t tiffReader) uint(e ifdEntry) uint32 {
+	if e.Type == 3 { // SHORT
+		return uint32(t.order.Uint16(e.Value))
+	}
+	return t.order.Uint32(e.Value)
+}
+
+// ascii reads an ASCII value, which is stored in the entry itself when it fits
+func (t tiffReader) ascii(e ifdEntry) string {
+	if e.Type != 2 {
+		return ""
+	}
+	value := e.Value
+	if e.Count > 4 {
+		offset := t.order.Uint32(e.Value)
+		if uint64(offset)+uint64(e.Count) > uint64(len(t.data)) {
+			return ""
+		}
+		value = t.data[offset : offset+e.Count]
+	} else {
+		value = value[:e.Count]
+	}
+	return strings.TrimRight(string(value), "\x00 ")
+}
+
+// parseTIFF reads the capture date and the embedded thumbnail from EXIF TIFF data
+func parseTIFF(data []byte) (exifData, error) {
+	if len(data) < 8 {
+		return exifData{}, fmt.Errorf("invalid EXIF data")
+	}
+	t := tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return exifData{}, fmt.Errorf("invalid EXIF byte order")
+	}
+
+	ifd0, next, ok := t.ifd(t.order.Uint32(data[4:]))
+	if !ok {
+		return exifData{}, fmt.Errorf("invalid EXIF data")
+	}
+
+	// Prefer when the photo was taken over when the file was last changed
+	dates := make(map[uint16]string)
+	result := exifData{Orientation: 1}
+	for _, e := range ifd0 {
+		switch e.Tag {
+		case tagOrientation:
+			if orientation := int(t.uint(e)); orientation >= 1 && orientation <= 8 {
+				result.Orientation = orientation
+			}
+		case tagDateTime:
+			dates[tagDateTime] = t.ascii(e)
+		case tagExifIFD:
+			exifIFD, _, ok := t.ifd(t.uint(e))
+			if !ok {
+				continue
+			}
+			for _, e := range exifIFD {
+				if e.Tag == tagDateTimeOriginal || e.Tag == tagDateTimeDigitized {
+					dates[e.Tag] = t.ascii(e)
+				}
+			}
+		}
+	}
+	for _, tag := range []uint16{tagDateTimeOriginal, tagDateTimeDigitized, tagDateTime} {
+		if taken, err := time.Parse(exifDateFormat, dates[tag]); err == nil {
+			result.Taken = taken
+			break
+		}
+	}
+
+	// The thumbnail is in the second directory, IFD1
+	if next != 0 {
+		if ifd1, _, ok := t.ifd(next); ok {
+			var offset, length uint32
+			for _, e := range ifd1 {
+				switch e.Tag {
+				case tagThumbnailOffset:
+					offset = t.uint(e)
+				case tagThumbnailLength:
+					length = t.uint(e)
+				}
+			}
+			if length > 0 && uint64(offset)+uint64(length) <= uint64(len(data)) {
+				result.Thumbnail = data[offset : offset+length]
+			}
+		}
+	}
+	return result, nil
+}
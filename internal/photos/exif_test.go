@@ -0,0 +1,93 @@
+package photos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// makeBox builds an ISO base media file box from its type and contents
+func makeBox(boxType string, contents ...[]byte) []byte {
+	data := bytes.Join(contents, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, boxType...), data...)
+}
+
+// testTIFF is the smallest TIFF structure: a header and an empty IFD
+var testTIFF = []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00")
+
+// testIINF lists one item, ID 1, of type Exif
+var testIINF = []byte("\x00\x00\x00\x00\x00\x01" + // Version 0, one entry
+	string(makeBox("infe", []byte("\x02\x00\x00\x00\x00\x01\x00\x00Exif\x00"))))
+
+// testILOC places item 1 at offset, 4-byte offsets and lengths
+func testILOC(offset, length uint32) []byte {
+	data := []byte("\x00\x00\x00\x00\x44\x00\x00\x01" + // Version 0, field sizes, one item
+		"\x00\x01\x00\x00\x00\x01") // Item 1, data reference 0, one extent
+	data = binary.BigEndian.AppendUint32(data, offset)
+	return binary.BigEndian.AppendUint32(data, length)
+}
+
+// makeHEIC builds a HEIC file holding only an Exif item, with the given item
+// info and location boxes; a nil iloc places the item after the meta box
+func makeHEIC(iinf, iloc []byte) []byte {
+	item := append([]byte("\x00\x00\x00\x06"), exifHeader...)
+	item = append(item, testTIFF...)
+
+	ftyp := makeBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	meta := func(iloc []byte) []byte {
+		return makeBox("meta", []byte("\x00\x00\x00\x00"), makeBox("iinf", iinf), makeBox("iloc", iloc))
+	}
+	if iloc == nil {
+		// The location box is the same size wherever the item is
+		offset := len(ftyp) + len(meta(testILOC(0, 0)))
+		iloc = testILOC(uint32(offset), uint32(len(item)))
+	}
+	return bytes.Join([][]byte{ftyp, meta(iloc), item}, nil)
+}
+
+func TestHeicEXIF(t *testing.T) {
+	tiff, err := heicEXIF(bytes.NewReader(makeHEIC(testIINF, nil)))
+	if err != nil {
+		t.Fatalf("heicEXIF() error = %v", err)
+	}
+	if !bytes.Equal(tiff, testTIFF) {
+		t.Errorf("heicEXIF() = %x, want %x", tiff, testTIFF)
+	}
+}
+
+func TestHeicEXIFTruncatedBoxes(t *testing.T) {
+	tests := []struct {
+		name string
+		iinf []byte
+		iloc []byte
+	}{
+		{"iinf without entry count", []byte("\x00\x00\x00\x00\x00"), nil},
+		{"iinf version 1 without 4-byte entry count", []byte("\x01\x00\x00\x00\x00\x00"), nil},
+		{"iinf version 1 with half an entry count", []byte("\x01\x00\x00\x00\x00\x00\x00"), nil},
+		{"iinf entry cut short", testIINF[:len(testIINF)-4], nil},
+		{"iloc without item count", testIINF, []byte("\x00\x00\x00\x00\x44\x00\x00")},
+		{"iloc version 2 without 4-byte item count", testIINF, []byte("\x02\x00\x00\x00\x44\x00\x00\x01")},
+		{"iloc version 2 with half an item count", testIINF, []byte("\x02\x00\x00\x00\x44\x00\x00\x00\x00")},
+		{"iloc item cut short", testIINF, testILOC(0, 0)[:12]},
+		{"iloc extent cut short", testIINF, testILOC(0, 0)[:18]},
+		{"iloc past the end of the file", testIINF, testILOC(1<<20, 32)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiff, _ := heicEXIF(bytes.NewReader(makeHEIC(tt.iinf, tt.iloc)))
+			if tiff != nil {
+				t.Errorf("heicEXIF() = %x, want nil", tiff)
+			}
+		})
+	}
+}
+
+func FuzzHeicEXIF(f *testing.F) {
+	f.Add(makeHEIC(testIINF, nil))
+	f.Add(makeHEIC([]byte("\x01\x00\x00\x00\x00\x00"), nil))
+	f.Add(makeHEIC(testIINF, []byte("\x02\x00\x00\x00\x44\x00\x00\x01")))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		heicEXIF(bytes.NewReader(data)) // Must not panic
+	})
+}
@@ -0,0 +1,131 @@
+package photos
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Where a photo's date was read from
+const (
+	SourceEXIF     = "exif"
+	SourceFilename = "filename"
+)
+
+// imageExtensions lists the photo types scanned, in lower case
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".heic": true,
+	".heif": true,
+}
+
+// filenameDateRegex finds a date in names such as IMG_20190314_101500.jpg,
+// PXL_20190314_101500123.jpg, IMG-20190314-WA0001.jpg or "Photo 2019-03-14 10.15.00.png"
+var filenameDateRegex = regexp.MustCompile(`(?:^|[^0-9])((?:19|20)\d{2})[-_.]?(0[1-9]|1[0-2])[-_.]?(0[1-9]|[12]\d|3[01])(?:[^0-9]|$)`)
+
+// Photo is an image file with the date it was taken
+type Photo struct {
+	Path       string
+	Date       time.Time
+	DateSource string // SourceEXIF or SourceFilename
+}
+
+// IsPhoto reports whether the file is of a type scanned for photos
+func IsPhoto(path string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// DateFromFilename reads the date in a photo's file name, as cameras and phones name them
+func DateFromFilename(path string) (time.Time, bool) {
+	m := filenameDateRegex.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("20060102", m[1]+m[2]+m[3])
+	return date, err == nil
+}
+
+// Read returns the photo at path, dated from its EXIF data or else its file name
+func Read(path string) (Photo, error) {
+	data, exifErr := readEXIF(path)
+	if !data.Taken.IsZero() {
+		return Photo{Path: path, Date: data.Taken, DateSource: SourceEXIF}, nil
+	}
+	if date, ok := DateFromFilename(path); ok {
+		return Photo{Path: path, Date: date, DateSource: SourceFilename}, nil
+	}
+	if exifErr != nil {
+		return Photo{}, fmt.Errorf("no date in EXIF data (%v) or file name", exifErr)
+	}
+	return Photo{}, fmt.Errorf("no date in EXIF data or file name")
+}
+
+// Scan reads every JPEG, PNG and HEIC file in the folder. Photos without a
+// date are left out, with a warning when verbose.
+func Scan(folderPath string, verbose bool) ([]Photo, error) {
+	var photos []Photo
+
+	err := filepath.WalkDir(folderPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !IsPhoto(path) {
+			return nil
+		}
+
+		photo, err := Read(path)
+		if err != nil {
+			if verbose {
+				fmt.Printf("Warning: Could not date photo %s: %v\n", path, err)
+			}
+			return nil // Continue processing other files
+		}
+		photos = append(photos, photo)
+		return nil
+	})
+
+	return photos, err
+}
+
+// Select returns the photos taken in earlier years on a day matching the
+// reference date, newest first
+func Select(photos []Photo, matcher markdown.DateMatcher, referenceDate time.Time) []Photo {
+	var selected []Photo
+	for _, photo := range photos {
+		if photo.Date.Year() < referenceDate.Year() && matcher(photo.Date, referenceDate) {
+			selected = append(selected, photo)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Date.After(selected[j].Date)
+	})
+	return selected
+}
+
+// YearGroup is the photos taken in one year
+type YearGroup struct {
+	Year   int
+	Photos []Photo
+}
+
+// GroupByYear groups photos by the year they were taken, keeping their order
+func GroupByYear(photos []Photo) []YearGroup {
+	var groups []YearGroup
+	for _, photo := range photos {
+		year := photo.Date.Year()
+		if len(groups) == 0 || groups[len(groups)-1].Year != year {
+			groups = append(groups, YearGroup{Year: year})
+		}
+		groups[len(groups)-1].Photos = append(groups[len(groups)-1].Photos, photo)
+	}
+	return groups
+}
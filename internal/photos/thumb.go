@@ -0,0 +1,134 @@
+package photos
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // Register PNG decoding for image.Decode
+	"os"
+)
+
+// thumbnailQuality is the JPEG quality of generated thumbnails
+const thumbnailQuality = 80
+
+// ErrNoThumbnail is returned for photos that can neither be decoded nor have
+// an embedded thumbnail, such as most HEIC files
+var ErrNoThumbnail = errors.New("no thumbnail available")
+
+// Thumbnail returns a JPEG of the photo fitting in a size by size square,
+// upright. It starts from the thumbnail embedded in the EXIF data when there
+// is one at least half that size, as decoding a full photo is slow.
+func Thumbnail(path string, size int) ([]byte, error) {
+	data, _ := readEXIF(path)
+	if data.Orientation == 0 {
+		data.Orientation = 1
+	}
+
+	var img image.Image
+	if data.Thumbnail != nil {
+		img, _ = jpeg.Decode(bytes.NewReader(data.Thumbnail))
+	}
+	// A thumbnail much smaller than asked for is blurry, so prefer the full
+	// photo; one at least half the size still looks sharp enough in the gallery
+	if img == nil || max(img.Bounds().Dx(), img.Bounds().Dy()) < size/2 {
+		if full, err := decodeFile(path); err == nil {
+			img = full
+		}
+	}
+	if img == nil {
+		return nil, ErrNoThumbnail
+	}
+
+	img = orient(scaleToFit(img, size), data.Orientation)
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, img, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// decodeFile decodes a JPEG or PNG file
+func decodeFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+// scaleToFit shrinks the image to fit in a size by size square, averaging
+// the pixels each new pixel covers. Smaller images are kept as they are.
+func scaleToFit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	newWidth, newHeight := size, height*size/width
+	if height > width {
+		newWidth, newHeight = width*size/height, size
+	}
+	newWidth, newHeight = max(newWidth, 1), max(newHeight, 1)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		y0, y1 := y*height/newHeight, max((y+1)*height/newHeight, y*height/newHeight+1)
+		for x := 0; x < newWidth; x++ {
+			x0, x1 := x*width/newWidth, max((x+1)*width/newWidth, x*width/newWidth+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			scaled.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+	return scaled
+}
+
+// orient turns an image the way its EXIF orientation says, so it shows upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Orientations 5 to 8 swap the width and height
+	newWidth, newHeight := width, height
+	if orientation >= 5 {
+		newWidth, newHeight = height, width
+	}
+
+	oriented := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var nx, ny int
+			switch orientation {
+			case 2: // Flipped horizontally
+				nx, ny = width-1-x, y
+			case 3: // Rotated 180°
+				nx, ny = width-1-x, height-1-y
+			case 4: // Flipped vertically
+				nx, ny = x, height-1-y
+			case 5: // Transposed
+				nx, ny = y, x
+			case 6: // Rotated 90° clockwise to show upright
+				nx, ny = height-1-y, x
+			case 7: // Transversed
+				nx, ny = height-1-y, width-1-x
+			case 8: // Rotated 90° anticlockwise to show upright
+				nx, ny = y, width-1-x
+			}
+			oriented.Set(nx, ny, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return oriented
+}
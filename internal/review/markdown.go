@@ -0,0 +1,94 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hashtagRegex matches the # starting an inline tag in quoted text
+var hashtagRegex = regexp.MustCompile(`(^|\s)#(\S)`)
+
+// wikilink links to a vault note by path, showing label. Inside tables the
+// alias separator is escaped so it does not end the cell.
+func wikilink(target, label string, inTable bool) string {
+	if label == "" || label == target {
+		return "[[" + target + "]]"
+	}
+	if inTable {
+		return "[[" + target + `\|` + label + "]]"
+	}
+	return "[[" + target + "|" + label + "]]"
+}
+
+// plural formats a count with its noun, such as "1 entry" or "3 entries"
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// writeLinked writes a section listing linked notes, if there are any
+func writeLinked(b *strings.Builder, title string, linked []Linked) {
+	if len(linked) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, l := range linked {
+		fmt.Fprintf(b, "- %s — %s\n", wikilink(l.ID, l.Label, false), plural(l.Count, "entry", "entries"))
+	}
+}
+
+// Markdown renders the review as an Obsidian note, linking to the entries it
+// mentions. Tags are quoted as code or escaped so the review does not take them on.
+func (r Review) Markdown() string {
+	var b strings.Builder
+	b.WriteString("---\ntags: [review]\n---\n")
+	fmt.Fprintf(&b, "# %d in Review\n\n", r.Year)
+	if r.Notes == 0 {
+		fmt.Fprintf(&b, "Nothing was written in %d.\n", r.Year)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s on %s, %s.\n", plural(r.Notes, "entry", "entries"), plural(r.Days, "day", "days"), plural(r.Words, "word", "words"))
+
+	b.WriteString("\n## Month by month\n\n")
+	b.WriteString("| Month | Entries | Words | First | Last |\n")
+	b.WriteString("| --- | ---: | ---: | --- | --- |\n")
+	for _, month := range r.Months {
+		first, last := "", ""
+		if month.First != nil {
+			first = wikilink(month.First.Link(), month.First.Date.Format("Jan 2"), true)
+			last = wikilink(month.Last.Link(), month.Last.Date.Format("Jan 2"), true)
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %s | %s |\n", month.Name, month.Notes, month.Words, first, last)
+	}
+
+	if len(r.Tags) > 0 {
+		b.WriteString("\n## Top tags\n\n")
+		for _, tag := range r.Tags {
+			fmt.Fprintf(&b, "- `#%s` — %s\n", tag.Tag, plural(tag.Count, "entry", "entries"))
+		}
+	}
+
+	writeLinked(&b, "People", r.People)
+	writeLinked(&b, "Places", r.Places)
+	writeLinked(&b, "Most linked", r.Linked)
+
+	b.WriteString("\n## Longest entries\n\n")
+	for _, entry := range r.Longest {
+		fmt.Fprintf(&b, "- %s — %s, %s\n", wikilink(entry.Link(), entry.Title, false), entry.Date.Format("Jan 2"), plural(entry.Words, "word", "words"))
+	}
+
+	if len(r.Highlights) > 0 {
+		b.WriteString("\n## Highlights\n")
+		for _, entry := range r.Highlights {
+			fmt.Fprintf(&b, "\n### %s — %s\n", entry.Date.Format("Monday, January 2"), wikilink(entry.Link(), entry.Title, false))
+			if entry.Excerpt != "" {
+				fmt.Fprintf(&b, "\n> %s\n", hashtagRegex.ReplaceAllString(entry.Excerpt, `$1\#$2`))
+			}
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,282 @@
+package review
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/graph"
+	"github.com/travis-mark/salthaven/internal/markdown"
+	"github.com/travis-mark/salthaven/internal/stats"
+)
+
+// Sizes of the report's lists
+const (
+	topTagsShown    = 10
+	topLinksShown   = 10
+	longestShown    = 5
+	highlightsShown = 12
+	excerptLength   = 200
+)
+
+// Tags that mark a linked note as a person or a place, and an entry as a highlight
+var (
+	personTags    = []string{"person", "people"}
+	placeTags     = []string{"place", "places", "location"}
+	highlightTags = []string{"highlight", "favorite", "favourite", "best"}
+)
+
+// Options configures a year in review
+type Options struct {
+	// Vault-relative folder of person notes; notes tagged #person count too
+	PeopleFolder string
+	// Vault-relative folder of place notes; notes tagged #place count too
+	PlacesFolder string
+}
+
+// Entry is a dated note of the year
+type Entry struct {
+	Path    string // Vault-relative path
+	Title   string // File name without .md
+	Date    time.Time
+	Words   int
+	Tags    []string
+	Excerpt string // Opening of the body, as plain text
+}
+
+// Link returns the wikilink Obsidian resolves to the entry
+func (e Entry) Link() string {
+	return strings.TrimSuffix(e.Path, path.Ext(e.Path))
+}
+
+// Month is one month of the year
+type Month struct {
+	Name  string
+	Notes int
+	Words int
+	First *Entry // Nil when nothing was written
+	Last  *Entry
+}
+
+// TagCount is a tag and the number of the year's entries using it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Linked is a note the year's entries link to, and how many of them do
+type Linked struct {
+	graph.Node
+	Count int
+}
+
+// Review summarises one year of journaling
+type Review struct {
+	Year       int
+	Notes      int
+	Words      int
+	Days       int // Days with at least one entry
+	Months     [12]Month
+	Tags       []TagCount
+	People     []Linked
+	Places     []Linked
+	Linked     []Linked // Most linked notes that are neither people nor places
+	Longest    []Entry
+	Highlights []Entry
+}
+
+// hasAnyTag reports whether tags include one of wanted, or a tag nested under one
+func hasAnyTag(tags []string, wanted []string) bool {
+	for _, tag := range wanted {
+		if markdown.HasTag(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether a vault-relative path is inside a vault-relative folder
+func inFolder(relPath, folder string) bool {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	return folder != "" && strings.HasPrefix(strings.ToLower(relPath), strings.ToLower(folder)+"/")
+}
+
+// Build gathers the review of a year from the vault's notes. Entries count in
+// the year of the first day they cover; links are counted once per entry.
+func Build(folderPath string, notes []markdown.Note, year int, opts Options) Review {
+	r := Review{Year: year}
+	for i := range r.Months {
+		r.Months[i].Name = time.Month(i + 1).String()
+	}
+
+	var entries []Entry
+	days := make(map[time.Time]bool)
+	tags := make(map[string]int)
+	for _, note := range notes {
+		if !note.Dated() || note.Date.Year() != year {
+			continue
+		}
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+		relPath = filepath.ToSlash(relPath)
+		entries = append(entries, Entry{
+			Path:    relPath,
+			Title:   strings.TrimSuffix(path.Base(relPath), path.Ext(relPath)),
+			Date:    note.Date,
+			Words:   stats.CountWords(note.Content),
+			Tags:    note.Tags,
+			Excerpt: markdown.Excerpt(note.Content, excerptLength),
+		})
+		days[time.Date(note.Date.Year(), note.Date.Month(), note.Date.Day(), 0, 0, 0, 0, time.UTC)] = true
+		for _, tag := range note.Tags {
+			tags[tag]++
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	r.Days = len(days)
+	for i := range entries {
+		entry := &entries[i]
+		month := &r.Months[entry.Date.Month()-1]
+		r.Notes++
+		r.Words += entry.Words
+		month.Notes++
+		month.Words += entry.Words
+		if month.First == nil {
+			month.First = entry
+		}
+		month.Last = entry
+	}
+
+	for tag, count := range tags {
+		r.Tags = append(r.Tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(r.Tags, func(i, j int) bool {
+		if r.Tags[i].Count != r.Tags[j].Count {
+			return r.Tags[i].Count > r.Tags[j].Count
+		}
+		return r.Tags[i].Tag < r.Tags[j].Tag
+	})
+	if len(r.Tags) > topTagsShown {
+		r.Tags = r.Tags[:topTagsShown]
+	}
+
+	g := graph.Build(folderPath, notes)
+	r.linked(g, entries, opts)
+
+	r.Longest = append([]Entry(nil), entries...)
+	sort.SliceStable(r.Longest, func(i, j int) bool {
+		return r.Longest[i].Words > r.Longest[j].Words
+	})
+	if len(r.Longest) > longestShown {
+		r.Longest = r.Longest[:longestShown]
+	}
+
+	r.Highlights = highlights(g, entries)
+
+	return r
+}
+
+// linked counts the notes the year's entries link to, sorting them into people,
+// places and everything else
+func (r *Review) linked(g *graph.Graph, entries []Entry, opts Options) {
+	year := make(map[string]bool)
+	for _, entry := range entries {
+		year[entry.Link()] = true
+	}
+
+	counts := make(map[string]int)
+	for _, edge := range g.Edges {
+		if year[edge.From] && !year[edge.To] {
+			counts[edge.To]++
+		}
+	}
+
+	var linked []Linked
+	for id, count := range counts {
+		if node, ok := g.Node(id); ok {
+			linked = append(linked, Linked{Node: node, Count: count})
+		}
+	}
+	sort.Slice(linked, func(i, j int) bool {
+		if linked[i].Count != linked[j].Count {
+			return linked[i].Count > linked[j].Count
+		}
+		return linked[i].Label < linked[j].Label
+	})
+
+	for _, l := range linked {
+		switch {
+		case inFolder(l.Path, opts.PeopleFolder) || hasAnyTag(l.Tags, personTags):
+			if len(r.People) < topLinksShown {
+				r.People = append(r.People, l)
+			}
+		case inFolder(l.Path, opts.PlacesFolder) || hasAnyTag(l.Tags, placeTags):
+			if len(r.Places) < topLinksShown {
+				r.Places = append(r.Places, l)
+			}
+		default:
+			if len(r.Linked) < topLinksShown {
+				r.Linked = append(r.Linked, l)
+			}
+		}
+	}
+}
+
+// highlights picks the entries tagged as highlights, then fills up with the
+// entries linked to most often, then the longest entry of each month
+func highlights(g *graph.Graph, entries []Entry) []Entry {
+	var picked []Entry
+	seen := make(map[string]bool)
+	add := func(entry Entry) {
+		if len(picked) < highlightsShown && !seen[entry.Path] {
+			seen[entry.Path] = true
+			picked = append(picked, entry)
+		}
+	}
+
+	for _, entry := range entries {
+		if hasAnyTag(entry.Tags, highlightTags) {
+			add(entry)
+		}
+	}
+
+	backlinks := make(map[string]int)
+	for _, edge := range g.Edges {
+		backlinks[edge.To]++
+	}
+	byLinks := append([]Entry(nil), entries...)
+	sort.SliceStable(byLinks, func(i, j int) bool {
+		return backlinks[byLinks[i].Link()] > backlinks[byLinks[j].Link()]
+	})
+	for _, entry := range byLinks {
+		if backlinks[entry.Link()] == 0 {
+			break
+		}
+		add(entry)
+	}
+
+	var longest [12]*Entry
+	for i := range entries {
+		month := entries[i].Date.Month() - 1
+		if longest[month] == nil || entries[i].Words > longest[month].Words {
+			longest[month] = &entries[i]
+		}
+	}
+	for _, entry := range longest {
+		if entry != nil {
+			add(*entry)
+		}
+	}
+
+	sort.SliceStable(picked, func(i, j int) bool {
+		return picked[i].Date.Before(picked[j].Date)
+	})
+	return picked
+}
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Token is a word of a note body, stemmed and lowercased, with its byte offsets in the body
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Tokenize splits text into stemmed, lowercased words. Apostrophes inside a
+// word are dropped, so "don't" indexes as "dont".
+func Tokenize(text string) []Token {
+	var tokens []Token
+	var word strings.Builder
+	start := -1
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, Token{Term: Stem(word.String()), Start: start, End: end})
+			word.Reset()
+			start = -1
+		}
+	}
+
+	for i, r := range text {
+		switch {
+		case isWordRune(r):
+			if start < 0 {
+				start = i
+			}
+			word.WriteRune(unicode.ToLower(r))
+		case (r == '\'' || r == '’') && start >= 0:
+			// Keep going if the apostrophe is followed by more of the word
+			next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+			if !isWordRune(next) {
+				flush(i)
+			}
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// Document is a note in the index
+type Document struct {
+	markdown.Note
+	RelPath string // Vault-relative path with forward slashes
+	Body    string // Content after the frontmatter
+	Tokens  []Token
+	// Number of times each term occurs in the body
+	TermCounts map[string]int
+}
+
+// Index is an inverted index from terms to the documents and positions they occur at
+type Index struct {
+	Docs []Document
+	// Token positions of each term, ascending, by document
+	postings  map[string]map[int][]int
+	avgLength float64
+	// TF-IDF vector of each document, for finding related notes
+	vectors []map[string]float64
+}
+
+// Build indexes the body of every note and computes the TF-IDF vectors used
+// to relate them. Paths are shown and filtered relative to folderPath.
+func Build(folderPath string, notes []markdown.Note) *Index {
+	idx := &Index{postings: make(map[string]map[int][]int)}
+	totalLength := 0
+
+	for _, note := range notes {
+		relPath, err := filepath.Rel(folderPath, note.Path)
+		if err != nil {
+			relPath = note.Path
+		}
+
+		body := markdown.Body(note.Content)
+		doc := Document{
+			Note:       note,
+			RelPath:    filepath.ToSlash(relPath),
+			Body:       body,
+			Tokens:     Tokenize(body),
+			TermCounts: make(map[string]int),
+		}
+		docID := len(idx.Docs)
+
+		positions := make(map[string][]int)
+		for pos, token := range doc.Tokens {
+			positions[token.Term] = append(positions[token.Term], pos)
+			doc.TermCounts[token.Term]++
+		}
+		for term, termPositions := range positions {
+			if idx.postings[term] == nil {
+				idx.postings[term] = make(map[int][]int)
+			}
+			idx.postings[term][docID] = termPositions
+		}
+
+		idx.Docs = append(idx.Docs, doc)
+		totalLength += len(doc.Tokens)
+	}
+
+	if len(idx.Docs) > 0 {
+		idx.avgLength = float64(totalLength) / float64(len(idx.Docs))
+	}
+	idx.buildVectors()
+	return idx
+}
+
+// DocFreq returns the number of documents containing term
+func (idx *Index) DocFreq(term string) int {
+	return len(idx.postings[term])
+}
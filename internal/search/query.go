@@ -0,0 +1,137 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed search query such as `beach "ice cream" tag:travel year:2019`
+type Query struct {
+	Terms   []string   // Stemmed words that must all occur
+	Phrases [][]string // Stemmed word sequences that must occur in order
+	Tags    []string   // Tags the note must have
+	Years   []int      // Years the note may be dated in
+	Paths   []string   // Substrings the vault-relative path must contain
+}
+
+// Empty reports whether the query has neither words nor filters
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0 && len(q.Tags) == 0 && len(q.Years) == 0 && len(q.Paths) == 0
+}
+
+// words returns every stemmed word the query needs, without duplicates
+func (q Query) words() []string {
+	seen := make(map[string]bool)
+	var words []string
+	add := func(word string) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	for _, term := range q.Terms {
+		add(term)
+	}
+	for _, phrase := range q.Phrases {
+		for _, term := range phrase {
+			add(term)
+		}
+	}
+	return words
+}
+
+// ParseQuery parses a search query. Words must all occur, in any form
+// ("walked" finds "walking"); "quoted phrases" must occur word for word; and
+// tag:NAME, year:YYYY and path:TEXT filter the notes searched.
+func ParseQuery(input string) (Query, error) {
+	var q Query
+
+	for _, part := range splitQuery(input) {
+		if part.quoted {
+			q.addWords(part.text)
+			continue
+		}
+
+		name, value, hasFilter := strings.Cut(part.text, ":")
+		switch strings.ToLower(name) {
+		case "tag":
+			if hasFilter && value != "" {
+				q.Tags = append(q.Tags, strings.TrimPrefix(value, "#"))
+				continue
+			}
+		case "year":
+			if hasFilter && value != "" {
+				year, err := strconv.Atoi(value)
+				if err != nil {
+					return Query{}, fmt.Errorf("invalid year %q, expected YYYY", value)
+				}
+				q.Years = append(q.Years, year)
+				continue
+			}
+		case "path":
+			if hasFilter && value != "" {
+				q.Paths = append(q.Paths, strings.ToLower(value))
+				continue
+			}
+		}
+		q.addWords(part.text)
+	}
+
+	return q, nil
+}
+
+// addWords adds a single word as a term, and several words as a phrase. A
+// quoted phrase and an unquoted word such as "re-read" both hold several.
+func (q *Query) addWords(text string) {
+	tokens := Tokenize(text)
+	switch len(tokens) {
+	case 0:
+		return
+	case 1:
+		q.Terms = append(q.Terms, tokens[0].Term)
+		return
+	}
+
+	phrase := make([]string, len(tokens))
+	for i, token := range tokens {
+		phrase[i] = token.Term
+	}
+	q.Phrases = append(q.Phrases, phrase)
+}
+
+// queryPart is a word or quoted phrase of a query
+type queryPart struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query on whitespace, keeping quoted phrases together.
+// An unclosed quote runs to the end of the query.
+func splitQuery(input string) []queryPart {
+	var parts []queryPart
+	var current strings.Builder
+	inQuote := false
+
+	flush := func() {
+		if current.Len() > 0 || inQuote {
+			parts = append(parts, queryPart{text: current.String(), quoted: inQuote})
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			flush()
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return parts
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// minSimilarity is the cosine similarity below which notes are not considered related
+const minSimilarity = 0.1
+
+// stopWords are common English words left out of similarity vectors, stemmed
+// as Tokenize stems them. Search still indexes them for phrase queries.
+var stopWords = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range []string{
+		"a", "about", "after", "again", "all", "also", "am", "an", "and", "any", "are", "as", "at",
+		"be", "because", "been", "before", "being", "but", "by", "can", "could", "did", "do", "does",
+		"doing", "dont", "down", "each", "few", "for", "from", "get", "got", "had", "has", "have",
+		"having", "he", "her", "here", "him", "his", "how", "i", "if", "im", "in", "into", "is", "it",
+		"its", "just", "me", "more", "most", "my", "no", "not", "now", "of", "off", "on", "once",
+		"only", "or", "other", "our", "out", "over", "really", "she", "so", "some", "still", "such",
+		"than", "that", "the", "their", "them", "then", "there", "these", "they", "this", "those",
+		"through", "to", "too", "up", "us", "very", "was", "we", "were", "what", "when", "where",
+		"which", "while", "who", "why", "will", "with", "would", "you", "your",
+	} {
+		words[Stem(word)] = true
+	}
+	return words
+}()
+
+// buildVectors computes a unit-length TF-IDF vector for every document.
+// Terms in every document and stop words carry no weight and are left out.
+func (idx *Index) buildVectors() {
+	n := float64(len(idx.Docs))
+	idx.vectors = make([]map[string]float64, len(idx.Docs))
+
+	for docID, doc := range idx.Docs {
+		vector := make(map[string]float64)
+		norm := 0.0
+		for term, count := range doc.TermCounts {
+			if stopWords[term] {
+				continue
+			}
+			idf := math.Log(n / float64(idx.DocFreq(term)))
+			if idf <= 0 {
+				continue
+			}
+			weight := (1 + math.Log(float64(count))) * idf
+			vector[term] = weight
+			norm += weight * weight
+		}
+
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		idx.vectors[docID] = vector
+	}
+}
+
+// similarity returns the cosine similarity of two documents
+func (idx *Index) similarity(a, b int) float64 {
+	va, vb := idx.vectors[a], idx.vectors[b]
+	if len(vb) < len(va) {
+		va, vb = vb, va
+	}
+	dot := 0.0
+	for term, weight := range va {
+		dot += weight * vb[term]
+	}
+	return dot
+}
+
+// Lookup returns the document for a vault-relative path
+func (idx *Index) Lookup(relPath string) (int, bool) {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	for docID, doc := range idx.Docs {
+		if doc.RelPath == relPath {
+			return docID, true
+		}
+	}
+	return 0, false
+}
+
+// Similar is a note related to another by the words they share
+type Similar struct {
+	Document
+	Score float64 // Cosine similarity, from 0 to 1
+}
+
+// Related returns up to limit notes most similar to a document, best first,
+// whatever their dates
+func (idx *Index) Related(docID int, limit int) []Similar {
+	var similar []Similar
+	for other := range idx.Docs {
+		if other == docID {
+			continue
+		}
+		if score := idx.similarity(docID, other); score >= minSimilarity {
+			similar = append(similar, Similar{Document: idx.Docs[other], Score: score})
+		}
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Score > similar[j].Score
+	})
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
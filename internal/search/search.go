@@ -0,0 +1,273 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Snippet sizes, in words
+const (
+	snippetWords  = 30
+	snippetBefore = 8
+)
+
+// Fragment is a piece of a snippet, highlighted if it matched the query
+type Fragment struct {
+	Text  string
+	Match bool
+}
+
+// Snippet is an excerpt of a note around the words that matched
+type Snippet []Fragment
+
+// Format renders the snippet as text, passing each matched word through highlight
+func (s Snippet) Format(highlight func(string) string) string {
+	var b strings.Builder
+	for _, fragment := range s {
+		if fragment.Match {
+			b.WriteString(highlight(fragment.Text))
+		} else {
+			b.WriteString(fragment.Text)
+		}
+	}
+	return b.String()
+}
+
+// Result is a note matching a query
+type Result struct {
+	Document
+	Score   float64
+	Snippet Snippet
+}
+
+// Search returns the notes matching the query, best first. Notes are ranked
+// by BM25 over the query words; a query with only filters lists the notes
+// that pass them, newest first.
+func (idx *Index) Search(q Query) []Result {
+	words := q.words()
+
+	var results []Result
+	for docID, doc := range idx.Docs {
+		if !idx.matches(docID, q, words) {
+			continue
+		}
+		positions := idx.matchPositions(docID, q)
+		results = append(results, Result{
+			Document: doc,
+			Score:    idx.score(docID, words),
+			Snippet:  makeSnippet(doc, positions),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Date.Equal(results[j].Date) {
+			return results[i].Date.After(results[j].Date)
+		}
+		return results[i].RelPath < results[j].RelPath
+	})
+	return results
+}
+
+// matches reports whether a document contains every word and phrase and passes the filters
+func (idx *Index) matches(docID int, q Query, words []string) bool {
+	doc := idx.Docs[docID]
+
+	for _, word := range words {
+		if _, ok := idx.postings[word][docID]; !ok {
+			return false
+		}
+	}
+	for _, phrase := range q.Phrases {
+		if len(idx.phraseStarts(docID, phrase)) == 0 {
+			return false
+		}
+	}
+
+	for _, tag := range q.Tags {
+		if !markdown.HasTag(doc.Tags, tag) {
+			return false
+		}
+	}
+	if len(q.Years) > 0 {
+		if !doc.Dated() {
+			return false
+		}
+		inYear := false
+		for _, year := range q.Years {
+			if year >= doc.Date.Year() && year <= doc.End.Year() {
+				inYear = true
+				break
+			}
+		}
+		if !inYear {
+			return false
+		}
+	}
+	path := strings.ToLower(doc.RelPath)
+	for _, part := range q.Paths {
+		if !strings.Contains(path, part) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// phraseStarts returns the positions at which a phrase occurs in a document
+func (idx *Index) phraseStarts(docID int, phrase []string) []int {
+	var starts []int
+	for _, start := range idx.postings[phrase[0]][docID] {
+		found := true
+		for i := 1; i < len(phrase); i++ {
+			positions := idx.postings[phrase[i]][docID]
+			n := sort.SearchInts(positions, start+i)
+			if n >= len(positions) || positions[n] != start+i {
+				found = false
+				break
+			}
+		}
+		if found {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// matchPositions returns the token positions to highlight: every occurrence
+// of a query term, and every word of each phrase occurrence
+func (idx *Index) matchPositions(docID int, q Query) map[int]bool {
+	positions := make(map[int]bool)
+	for _, term := range q.Terms {
+		for _, pos := range idx.postings[term][docID] {
+			positions[pos] = true
+		}
+	}
+	for _, phrase := range q.Phrases {
+		for _, start := range idx.phraseStarts(docID, phrase) {
+			for i := range phrase {
+				positions[start+i] = true
+			}
+		}
+	}
+	return positions
+}
+
+// score sums the BM25 weight of each word in a document
+func (idx *Index) score(docID int, words []string) float64 {
+	n := float64(len(idx.Docs))
+	length := float64(len(idx.Docs[docID].Tokens))
+
+	score := 0.0
+	for _, word := range words {
+		df := float64(idx.DocFreq(word))
+		tf := float64(len(idx.postings[word][docID]))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		norm := 1 - bm25B
+		if idx.avgLength > 0 {
+			norm += bm25B * length / idx.avgLength
+		}
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+	return score
+}
+
+// makeSnippet excerpts the part of a document with the most highlighted words
+func makeSnippet(doc Document, positions map[int]bool) Snippet {
+	tokens := doc.Tokens
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Start a little before the window of snippetWords holding the most matches
+	first := 0
+	if len(positions) > 0 {
+		best := -1
+		for pos := range positions {
+			count := 0
+			for i := pos; i < pos+snippetWords && i < len(tokens); i++ {
+				if positions[i] {
+					count++
+				}
+			}
+			if count > best || (count == best && pos < first) {
+				best, first = count, pos
+			}
+		}
+		first -= snippetBefore
+		if first < 0 {
+			first = 0
+		}
+	}
+	last := first + snippetWords
+	if last > len(tokens) {
+		last = len(tokens)
+	}
+
+	var snippet Snippet
+	if first > 0 {
+		snippet = append(snippet, Fragment{Text: "…"})
+	}
+	offset := tokens[first].Start
+	for i := first; i < last; i++ {
+		if !positions[i] {
+			continue
+		}
+		gap := collapseSpace(doc.Body[offset:tokens[i].Start])
+		word := doc.Body[tokens[i].Start:tokens[i].End]
+		offset = tokens[i].End
+
+		// Highlight the words of a phrase together
+		if n := len(snippet); n > 0 && snippet[n-1].Match && strings.TrimSpace(gap) == "" {
+			snippet[n-1].Text += gap + word
+			continue
+		}
+		if gap != "" {
+			snippet = append(snippet, Fragment{Text: gap})
+		}
+		snippet = append(snippet, Fragment{Text: word, Match: true})
+	}
+
+	// Keep the closing punctuation when the snippet runs to the end of the note
+	end := tokens[last-1].End
+	if last == len(tokens) {
+		end = len(strings.TrimRightFunc(doc.Body, unicode.IsSpace))
+	}
+	if rest := doc.Body[offset:end]; rest != "" {
+		snippet = append(snippet, Fragment{Text: collapseSpace(rest)})
+	}
+	if last < len(tokens) {
+		snippet = append(snippet, Fragment{Text: "…"})
+	}
+	return snippet
+}
+
+// collapseSpace replaces each run of whitespace, including newlines, with a single space
+func collapseSpace(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// Heatmap metrics
+const (
+	MetricNotes = "notes"
+	MetricWords = "words"
+)
+
+// HeatmapLevels is the number of intensity levels above zero
+const HeatmapLevels = 4
+
+// ParseMetric checks a heatmap metric name, defaulting to notes
+func ParseMetric(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", MetricNotes:
+		return MetricNotes, nil
+	case MetricWords:
+		return MetricWords, nil
+	}
+	return "", fmt.Errorf("unknown heatmap metric %q (want notes or words)", value)
+}
+
+// HeatmapDay is one cell of the heatmap
+type HeatmapDay struct {
+	Date  time.Time
+	Notes int
+	Words int
+	Value int // Notes or words, by the heatmap's metric
+	Level int // 0 for nothing written, up to HeatmapLevels for the busiest days
+}
+
+// Week returns the column of the day within its year, weeks starting on Monday
+func (d HeatmapDay) Week() int {
+	jan1 := time.Date(d.Date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	return (d.Date.YearDay() - 1 + Weekday(jan1)) / 7
+}
+
+// Weekday returns the row of a date, 0 for Monday through 6 for Sunday
+func Weekday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// HeatmapYear is every day of one year
+type HeatmapYear struct {
+	Year  int
+	Days  []HeatmapDay
+	Notes int
+	Words int
+}
+
+// Weeks returns the number of week columns the year spans
+func (y HeatmapYear) Weeks() int {
+	if len(y.Days) == 0 {
+		return 0
+	}
+	return y.Days[len(y.Days)-1].Week() + 1
+}
+
+// Heatmap counts the notes and words written on every day of the given years,
+// or of every year from the first dated note to today when years is empty.
+// Levels are scaled to the busiest day across all the years, so years compare.
+func Heatmap(notes []markdown.Note, metric string, years []int, today time.Time) []HeatmapYear {
+	days := make(map[time.Time]*HeatmapDay)
+	first := today.Year()
+	for _, note := range notes {
+		if !note.Dated() {
+			continue
+		}
+		date := civilDate(note.Date)
+		if days[date] == nil {
+			days[date] = &HeatmapDay{Date: date}
+		}
+		days[date].Notes++
+		days[date].Words += CountWords(note.Content)
+		first = min(first, date.Year())
+	}
+
+	if len(years) == 0 {
+		for year := first; year <= today.Year(); year++ {
+			years = append(years, year)
+		}
+	}
+
+	var heatmap []HeatmapYear
+	largest := 0
+	for _, year := range years {
+		heatmapYear := HeatmapYear{Year: year}
+		for date := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); date.Year() == year; date = date.AddDate(0, 0, 1) {
+			day := HeatmapDay{Date: date}
+			if counted := days[date]; counted != nil {
+				day = *counted
+			}
+			day.Value = day.Notes
+			if metric == MetricWords {
+				day.Value = day.Words
+			}
+			largest = max(largest, day.Value)
+			heatmapYear.Notes += day.Notes
+			heatmapYear.Words += day.Words
+			heatmapYear.Days = append(heatmapYear.Days, day)
+		}
+		heatmap = append(heatmap, heatmapYear)
+	}
+
+	// Scale each day between 1 and HeatmapLevels by its share of the busiest day
+	for y := range heatmap {
+		for d := range heatmap[y].Days {
+			if value := heatmap[y].Days[d].Value; value > 0 {
+				heatmap[y].Days[d].Level = (value*HeatmapLevels + largest - 1) / largest
+			}
+		}
+	}
+
+	return heatmap
+}
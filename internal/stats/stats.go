@@ -0,0 +1,204 @@
+package stats
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/travis-mark/salthaven/internal/markdown"
+)
+
+// busiestDaysShown is the number of busiest days reported
+const busiestDaysShown = 5
+
+// Count is a labelled number of notes
+type Count struct {
+	Label string
+	Notes int
+	Words int
+}
+
+// Streak is a run of consecutive days with at least one note
+type Streak struct {
+	Start time.Time
+	End   time.Time
+	Days  int
+}
+
+// String describes the streak, such as "12 days (Jan 1, 2020 – Jan 12, 2020)"
+func (s Streak) String() string {
+	switch s.Days {
+	case 0:
+		return "none"
+	case 1:
+		return "1 day (" + s.End.Format("Jan 2, 2006") + ")"
+	}
+	return fmt.Sprintf("%d days (%s – %s)", s.Days, s.Start.Format("Jan 2, 2006"), s.End.Format("Jan 2, 2006"))
+}
+
+// Day is the notes written on one date
+type Day struct {
+	Date  time.Time
+	Notes int
+	Words int
+}
+
+// Stats summarises a vault's journaling
+type Stats struct {
+	Notes     int // Every markdown note
+	Dated     int
+	Words     int       // Words in the bodies of dated notes
+	Undated   []string  // Vault-relative paths of notes without a date
+	ByYear    []Count   // Oldest year first, including years with no notes in between
+	ByMonth   [12]Count // January first
+	ByWeekday [7]Count  // Monday first
+	Longest   Streak
+	Current   Streak // Ends today, or yesterday if nothing is written yet today
+	Busiest   []Day  // Dates with the most notes, then words
+}
+
+// CountWords returns the number of words in a note's body
+func CountWords(content string) int {
+	return len(strings.Fields(markdown.Body(content)))
+}
+
+// civilDate strips the time of day and location, so dates compare by calendar day
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Compute gathers statistics for the notes of a vault. Each dated note counts
+// on the first day it covers.
+func Compute(folderPath string, notes []markdown.Note, today time.Time) Stats {
+	var s Stats
+	for i := range s.ByMonth {
+		s.ByMonth[i].Label = time.Month(i + 1).String()
+	}
+	for i := range s.ByWeekday {
+		s.ByWeekday[i].Label = time.Weekday((i + 1) % 7).String()
+	}
+
+	years := make(map[int]*Count)
+	days := make(map[time.Time]*Day)
+
+	for _, note := range notes {
+		s.Notes++
+		if !note.Dated() {
+			relPath, err := filepath.Rel(folderPath, note.Path)
+			if err != nil {
+				relPath = note.Path
+			}
+			s.Undated = append(s.Undated, relPath)
+			continue
+		}
+
+		words := CountWords(note.Content)
+		s.Dated++
+		s.Words += words
+
+		date := civilDate(note.Date)
+		if years[date.Year()] == nil {
+			years[date.Year()] = &Count{Label: strconv.Itoa(date.Year())}
+		}
+		years[date.Year()].Notes++
+		years[date.Year()].Words += words
+
+		s.ByMonth[date.Month()-1].Notes++
+		s.ByMonth[date.Month()-1].Words += words
+		weekday := (int(date.Weekday()) + 6) % 7
+		s.ByWeekday[weekday].Notes++
+		s.ByWeekday[weekday].Words += words
+
+		if days[date] == nil {
+			days[date] = &Day{Date: date}
+		}
+		days[date].Notes++
+		days[date].Words += words
+	}
+
+	// Years in order, keeping gaps visible
+	if len(years) > 0 {
+		first, last := today.Year(), 0
+		for year := range years {
+			first = min(first, year)
+			last = max(last, year)
+		}
+		for year := first; year <= last; year++ {
+			if count := years[year]; count != nil {
+				s.ByYear = append(s.ByYear, *count)
+			} else {
+				s.ByYear = append(s.ByYear, Count{Label: strconv.Itoa(year)})
+			}
+		}
+	}
+
+	var dates []time.Time
+	for date, day := range days {
+		dates = append(dates, date)
+		s.Busiest = append(s.Busiest, *day)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	s.Longest = longestStreak(dates)
+	s.Current = currentStreak(days, civilDate(today))
+
+	sort.Slice(s.Busiest, func(i, j int) bool {
+		a, b := s.Busiest[i], s.Busiest[j]
+		if a.Notes != b.Notes {
+			return a.Notes > b.Notes
+		}
+		if a.Words != b.Words {
+			return a.Words > b.Words
+		}
+		return a.Date.After(b.Date)
+	})
+	if len(s.Busiest) > busiestDaysShown {
+		s.Busiest = s.Busiest[:busiestDaysShown]
+	}
+
+	return s
+}
+
+// longestStreak finds the longest run of consecutive dates. Dates must be sorted and distinct.
+func longestStreak(dates []time.Time) Streak {
+	var longest, run Streak
+	for _, date := range dates {
+		if run.Days > 0 && date.Equal(run.End.AddDate(0, 0, 1)) {
+			run.End = date
+			run.Days++
+		} else {
+			run = Streak{Start: date, End: date, Days: 1}
+		}
+		if run.Days > longest.Days {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// currentStreak counts back the consecutive days with notes from today, or
+// from yesterday when nothing is written yet today
+func currentStreak(days map[time.Time]*Day, today time.Time) Streak {
+	end := today
+	if days[end] == nil {
+		end = today.AddDate(0, 0, -1)
+	}
+
+	streak := Streak{End: end}
+	for date := end; days[date] != nil; date = date.AddDate(0, 0, -1) {
+		streak.Start = date
+		streak.Days++
+	}
+	return streak
+}
+
+// Max returns the largest note count among counts, for scaling charts
+func Max(counts []Count) int {
+	largest := 0
+	for _, count := range counts {
+		largest = max(largest, count.Notes)
+	}
+	return largest
+}
@@ -7,9 +7,22 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/travis-mark/salthaven/cmd/appendnote"
+	"github.com/travis-mark/salthaven/cmd/exportics"
+	"github.com/travis-mark/salthaven/cmd/graph"
+	"github.com/travis-mark/salthaven/cmd/heatmap"
 	"github.com/travis-mark/salthaven/cmd/list"
+	"github.com/travis-mark/salthaven/cmd/newnote"
+	"github.com/travis-mark/salthaven/cmd/random"
+	"github.com/travis-mark/salthaven/cmd/related"
+	"github.com/travis-mark/salthaven/cmd/review"
+	"github.com/travis-mark/salthaven/cmd/search"
 	"github.com/travis-mark/salthaven/cmd/serve"
+	"github.com/travis-mark/salthaven/cmd/stats"
+	"github.com/travis-mark/salthaven/cmd/tasks"
+	"github.com/travis-mark/salthaven/internal/markdown"
 )
 
 // loadEnvFile loads environment variables from a .env file
@@ -61,14 +74,136 @@ func getDefaultFolderPath() string {
 	return "."
 }
 
+// getLeapDayPolicy returns the leap day policy from SALTHAVEN_LEAP_DAY, or the default
+func getLeapDayPolicy() markdown.LeapDayPolicy {
+	policy, err := markdown.ParseLeapDayPolicy(os.Getenv("SALTHAVEN_LEAP_DAY"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return policy
+}
+
+// getDateProperties returns the frontmatter date properties from SALTHAVEN_DATE_PROPERTIES, or the default
+func getDateProperties() []string {
+	if properties := splitList(os.Getenv("SALTHAVEN_DATE_PROPERTIES")); len(properties) > 0 {
+		return properties
+	}
+	return markdown.DefaultDateProperties
+}
+
+// getNearestWindow returns the nearest-date fallback window from SALTHAVEN_NEAREST, or zero to disable it
+func getNearestWindow() int {
+	value := os.Getenv("SALTHAVEN_NEAREST")
+	if value == "" {
+		return 0
+	}
+	days, err := markdown.ParseDays(value)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return days
+}
+
+// getEnvOr returns the environment variable, or fallback when it is unset
+func getEnvOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// splitList splits a comma-separated argument, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseYears parses a comma-separated list of years
+func parseYears(value string) ([]int, error) {
+	var years []int
+	for _, item := range splitList(value) {
+		year, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid year %q, expected YYYY", item)
+		}
+		years = append(years, year)
+	}
+	return years, nil
+}
+
+// parseDateArg parses a YYYY-MM-DD argument, keeping the current time of day
+func parseDateArg(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	now := time.Now()
+	return time.Date(date.Year(), date.Month(), date.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.Local), nil
+}
+
 func usage() {
 	fmt.Println("Usage: salthaven <command> [folder_path] [options] [args...]")
 	fmt.Println("Commands:")
-	fmt.Println("  list           List markdown notes matching today's date")
+	fmt.Println("  list           List markdown notes and .ics calendar events matching today's date")
 	fmt.Println("  serve          Serve a web page with today's entries")
+	fmt.Println("  export-site    Export the day, note and calendar pages as a static site, e.g. export-site ./site")
+	fmt.Println("  export-ics     Export dated notes as all-day events to an iCalendar file")
+	fmt.Println("  new            Create today's daily note from the vault template")
+	fmt.Println("  append         Append a timestamped entry to today's daily note")
+	fmt.Println("  tasks          List tasks from dated notes")
+	fmt.Println("  stats          Summarise notes per year, month and weekday, words and streaks")
+	fmt.Println("  heatmap        Draw a calendar heatmap of notes written each day")
+	fmt.Println("  review         Write a year in review as Markdown, e.g. review 2025 (default: last year)")
+	fmt.Println("  graph          Export the vault's link graph as dot, graphml or json")
+	fmt.Println("  random         Show a random dated note")
+	fmt.Println("  related        List the notes most similar to a note, e.g. related Journal/2019-07-04.md")
+	fmt.Println("  search         Search the text of every note, e.g. search \"ice cream\" tag:travel year:2019")
 	fmt.Println("Options:")
 	fmt.Println("  -v, --verbose  Enable verbose output (show warnings)")
 	fmt.Println("  -p, --port     Port number for serve command (default: 8080)")
+	fmt.Println("  --host         Address serve listens on (default: 127.0.0.1, or SALTHAVEN_HOST);")
+	fmt.Println("                 use 0.0.0.0 to let other machines reach it")
+	fmt.Println("  -m, --match    Date match expression for list, serve and export-site (default: sameday),")
+	fmt.Println("                 e.g. \"sameday or window:3d\". Terms: sameday, exact, sameweek,")
+	fmt.Println("                 samemonth, weekdayofmonth, window:N[d|w], yearsago:N, range:FROM..TO;")
+	fmt.Println("                 combine with and, or, not and parentheses")
+	fmt.Println("  --leap-day     When February 29 notes show in non-leap years: feb28, mar1,")
+	fmt.Println("                 both or exact (default: feb28, or SALTHAVEN_LEAP_DAY)")
+	fmt.Println("  --nearest      When nothing matches, show the closest notes within N[d|w] days")
+	fmt.Println("                 of the date in any year, e.g. 7d (or SALTHAVEN_NEAREST)")
+	fmt.Println("  -d, --digest   List the whole week or month from previous years: week or month")
+	fmt.Println("  --date-properties  Frontmatter properties holding dates, comma-separated")
+	fmt.Println("                 (default: date, or SALTHAVEN_DATE_PROPERTIES)")
+	fmt.Println("  --events       YAML or CSV file of birthdays and anniversaries (or SALTHAVEN_EVENTS)")
+	fmt.Println("  --people       Folder of person notes with birthday properties (or SALTHAVEN_PEOPLE_FOLDER)")
+	fmt.Println("  --places       Folder of place notes, for the year in review (or SALTHAVEN_PLACES_FOLDER)")
+	fmt.Println("  --photos       Folder of JPEG, PNG and HEIC photos to show on the day they were taken")
+	fmt.Println("                 in serve (or SALTHAVEN_PHOTOS_FOLDER)")
+	fmt.Println("  --git          Git repositories whose commits from this day list and serve show,")
+	fmt.Println("                 repeatable or comma-separated (or SALTHAVEN_GIT_REPOS)")
+	fmt.Println("  --git-author   Whose commits to show, by name or email (default: each repository's")
+	fmt.Println("                 user.email, or SALTHAVEN_GIT_AUTHOR)")
+	fmt.Println("  --tag          Only list, pick or export notes with this tag (repeatable or comma-separated)")
+	fmt.Println("  --exclude-tag  Skip notes with this tag (repeatable or comma-separated)")
+	fmt.Println("  --year         Only pick random notes from, or draw the heatmap of, this year")
+	fmt.Println("                 (repeatable or comma-separated)")
+	fmt.Println("  --metric       Heatmap intensity by notes or words (default: notes)")
+	fmt.Println("  --anniversaries  Repeat exported calendar events every year on the note's date")
+	fmt.Println("  --older        Pick older random notes more often")
+	fmt.Println("  --date         Date of the note for new command (YYYY-MM-DD, default: today)")
+	fmt.Println("  --template     Template file for new command (default: daily notes setting)")
+	fmt.Println("  -t, --task     Append an open task instead of a timestamped bullet")
+	fmt.Println("  -s, --status   Tasks to list: open, done, overdue or all (default: open)")
+	fmt.Println("  -f, --format   Graph format: dot, graphml or json (default: dot)")
+	fmt.Println("  -o, --output   Vault note to write the review to, or .ics file to export to,")
+	fmt.Println("                 instead of printing it")
+	fmt.Println("  --force        Replace the review note if it exists")
+	fmt.Println("  -n, --limit    Most search results or related notes to show, 0 for all (default: 20, 5)")
 }
 
 func main() {
@@ -82,44 +217,670 @@ func main() {
 	switch command {
 	case "list":
 		folderPath := getDefaultFolderPath()
-		verbose := false
+		opts := list.Options{
+			Match:          markdown.DefaultMatchExpr,
+			LeapDay:        getLeapDayPolicy(),
+			DateProperties: getDateProperties(),
+			EventsFile:     getEnvOr("SALTHAVEN_EVENTS", ""),
+			PeopleFolder:   getEnvOr("SALTHAVEN_PEOPLE_FOLDER", ""),
+			GitRepos:       splitList(os.Getenv("SALTHAVEN_GIT_REPOS")),
+			GitAuthor:      getEnvOr("SALTHAVEN_GIT_AUTHOR", ""),
+			Nearest:        getNearestWindow(),
+		}
 
 		// Parse arguments
 		for i := 2; i < len(os.Args); i++ {
 			arg := os.Args[i]
 			if arg == "-v" || arg == "--verbose" {
-				verbose = true
+				opts.Verbose = true
+			} else if arg == "-m" || arg == "--match" {
+				if i+1 < len(os.Args) {
+					opts.Match = os.Args[i+1]
+					i++ // Skip the expression argument
+				}
+			} else if arg == "--leap-day" {
+				if i+1 < len(os.Args) {
+					policy, err := markdown.ParseLeapDayPolicy(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.LeapDay = policy
+					i++ // Skip the policy argument
+				}
+			} else if arg == "-d" || arg == "--digest" {
+				if i+1 < len(os.Args) {
+					digest, err := markdown.ParseDigest(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Digest = digest
+					i++ // Skip the digest argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else if arg == "--nearest" {
+				if i+1 < len(os.Args) {
+					days, err := markdown.ParseDays(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Nearest = days
+					i++ // Skip the window argument
+				}
+			} else if arg == "--events" {
+				if i+1 < len(os.Args) {
+					opts.EventsFile = os.Args[i+1]
+					i++ // Skip the events file argument
+				}
+			} else if arg == "--people" {
+				if i+1 < len(os.Args) {
+					opts.PeopleFolder = os.Args[i+1]
+					i++ // Skip the people folder argument
+				}
+			} else if arg == "--git" {
+				if i+1 < len(os.Args) {
+					opts.GitRepos = append(opts.GitRepos, splitList(os.Args[i+1])...)
+					i++ // Skip the repositories argument
+				}
+			} else if arg == "--git-author" {
+				if i+1 < len(os.Args) {
+					opts.GitAuthor = os.Args[i+1]
+					i++ // Skip the author argument
+				}
+			} else if arg == "--tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Include = append(opts.Tags.Include, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
+			} else if arg == "--exclude-tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Exclude = append(opts.Tags.Exclude, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
 			} else {
 				folderPath = arg
 			}
 		}
 
-		if err := list.Execute(folderPath, verbose); err != nil {
+		if err := list.Execute(folderPath, opts); err != nil {
 			log.Fatal(err)
 		}
 	case "serve":
 		folderPath := getDefaultFolderPath()
-		verbose := false
-		port := 8080
+		opts := serve.Options{
+			Host:           getEnvOr("SALTHAVEN_HOST", serve.DefaultHost),
+			Port:           8080,
+			Match:          markdown.DefaultMatchExpr,
+			LeapDay:        getLeapDayPolicy(),
+			DateProperties: getDateProperties(),
+			EventsFile:     getEnvOr("SALTHAVEN_EVENTS", ""),
+			PeopleFolder:   getEnvOr("SALTHAVEN_PEOPLE_FOLDER", ""),
+			PlacesFolder:   getEnvOr("SALTHAVEN_PLACES_FOLDER", ""),
+			PhotosFolder:   getEnvOr("SALTHAVEN_PHOTOS_FOLDER", ""),
+			GitRepos:       splitList(os.Getenv("SALTHAVEN_GIT_REPOS")),
+			GitAuthor:      getEnvOr("SALTHAVEN_GIT_AUTHOR", ""),
+			Nearest:        getNearestWindow(),
+		}
 
 		// Parse arguments
 		for i := 2; i < len(os.Args); i++ {
 			arg := os.Args[i]
 			if arg == "-v" || arg == "--verbose" {
-				verbose = true
+				opts.Verbose = true
+			} else if arg == "--host" {
+				if i+1 < len(os.Args) {
+					opts.Host = os.Args[i+1]
+					i++ // Skip the host argument
+				}
 			} else if arg == "-p" || arg == "--port" {
 				if i+1 < len(os.Args) {
 					if p, err := strconv.Atoi(os.Args[i+1]); err == nil {
-						port = p
+						opts.Port = p
 						i++ // Skip the port number argument
 					}
 				}
+			} else if arg == "-m" || arg == "--match" {
+				if i+1 < len(os.Args) {
+					opts.Match = os.Args[i+1]
+					i++ // Skip the expression argument
+				}
+			} else if arg == "--leap-day" {
+				if i+1 < len(os.Args) {
+					policy, err := markdown.ParseLeapDayPolicy(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.LeapDay = policy
+					i++ // Skip the policy argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else if arg == "--nearest" {
+				if i+1 < len(os.Args) {
+					days, err := markdown.ParseDays(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Nearest = days
+					i++ // Skip the window argument
+				}
+			} else if arg == "--events" {
+				if i+1 < len(os.Args) {
+					opts.EventsFile = os.Args[i+1]
+					i++ // Skip the events file argument
+				}
+			} else if arg == "--people" {
+				if i+1 < len(os.Args) {
+					opts.PeopleFolder = os.Args[i+1]
+					i++ // Skip the people folder argument
+				}
+			} else if arg == "--places" {
+				if i+1 < len(os.Args) {
+					opts.PlacesFolder = os.Args[i+1]
+					i++ // Skip the places folder argument
+				}
+			} else if arg == "--photos" {
+				if i+1 < len(os.Args) {
+					opts.PhotosFolder = os.Args[i+1]
+					i++ // Skip the photos folder argument
+				}
+			} else if arg == "--git" {
+				if i+1 < len(os.Args) {
+					opts.GitRepos = append(opts.GitRepos, splitList(os.Args[i+1])...)
+					i++ // Skip the repositories argument
+				}
+			} else if arg == "--git-author" {
+				if i+1 < len(os.Args) {
+					opts.GitAuthor = os.Args[i+1]
+					i++ // Skip the author argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := serve.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "export-site":
+		folderPath := getDefaultFolderPath()
+		opts := serve.Options{
+			Match:          markdown.DefaultMatchExpr,
+			LeapDay:        getLeapDayPolicy(),
+			DateProperties: getDateProperties(),
+			EventsFile:     getEnvOr("SALTHAVEN_EVENTS", ""),
+			PeopleFolder:   getEnvOr("SALTHAVEN_PEOPLE_FOLDER", ""),
+			Nearest:        getNearestWindow(),
+		}
+		var positional []string
+
+		// Parse arguments: the last positional argument is the output folder, an earlier one the vault
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-m" || arg == "--match" {
+				if i+1 < len(os.Args) {
+					opts.Match = os.Args[i+1]
+					i++ // Skip the expression argument
+				}
+			} else if arg == "--leap-day" {
+				if i+1 < len(os.Args) {
+					policy, err := markdown.ParseLeapDayPolicy(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.LeapDay = policy
+					i++ // Skip the policy argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else if arg == "--nearest" {
+				if i+1 < len(os.Args) {
+					days, err := markdown.ParseDays(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Nearest = days
+					i++ // Skip the window argument
+				}
+			} else if arg == "--events" {
+				if i+1 < len(os.Args) {
+					opts.EventsFile = os.Args[i+1]
+					i++ // Skip the events file argument
+				}
+			} else if arg == "--people" {
+				if i+1 < len(os.Args) {
+					opts.PeopleFolder = os.Args[i+1]
+					i++ // Skip the people folder argument
+				}
+			} else {
+				positional = append(positional, arg)
+			}
+		}
+		if len(positional) == 0 {
+			log.Fatal("usage: salthaven export-site [folder_path] <outdir>")
+		}
+		if len(positional) > 1 {
+			folderPath = positional[0]
+		}
+		outDir := positional[len(positional)-1]
+
+		if err := serve.ExportSite(folderPath, outDir, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "export-ics":
+		folderPath := getDefaultFolderPath()
+		opts := exportics.Options{DateProperties: getDateProperties()}
+		opts.LeapDay = getLeapDayPolicy()
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-o" || arg == "--output" {
+				if i+1 < len(os.Args) {
+					opts.Output = os.Args[i+1]
+					i++ // Skip the output argument
+				}
+			} else if arg == "--anniversaries" {
+				opts.Anniversaries = true
+			} else if arg == "--leap-day" {
+				if i+1 < len(os.Args) {
+					policy, err := markdown.ParseLeapDayPolicy(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.LeapDay = policy
+					i++ // Skip the policy argument
+				}
+			} else if arg == "--tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Include = append(opts.Tags.Include, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
+			} else if arg == "--exclude-tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Exclude = append(opts.Tags.Exclude, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := exportics.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "new":
+		folderPath := getDefaultFolderPath()
+		verbose := false
+		date := time.Now()
+		templatePath := ""
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				verbose = true
+			} else if arg == "--date" {
+				if i+1 < len(os.Args) {
+					d, err := parseDateArg(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					date = d
+					i++ // Skip the date argument
+				}
+			} else if arg == "--template" {
+				if i+1 < len(os.Args) {
+					templatePath = os.Args[i+1]
+					i++ // Skip the template argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := newnote.Execute(folderPath, verbose, date, templatePath); err != nil {
+			log.Fatal(err)
+		}
+	case "append":
+		folderPath := getDefaultFolderPath()
+		verbose := false
+		task := false
+		var positional []string
+
+		// Parse arguments: the last positional argument is the text, an earlier one the folder
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				verbose = true
+			} else if arg == "-t" || arg == "--task" {
+				task = true
+			} else {
+				positional = append(positional, arg)
+			}
+		}
+		if len(positional) == 0 {
+			log.Fatal("usage: salthaven append [folder_path] [-t] \"text\"")
+		}
+		if len(positional) > 1 {
+			folderPath = positional[0]
+		}
+		text := positional[len(positional)-1]
+
+		if err := appendnote.Execute(folderPath, verbose, text, task); err != nil {
+			log.Fatal(err)
+		}
+	case "tasks":
+		folderPath := getDefaultFolderPath()
+		verbose := false
+		status := "open"
+		dateProperties := getDateProperties()
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				verbose = true
+			} else if arg == "-s" || arg == "--status" {
+				if i+1 < len(os.Args) {
+					status = os.Args[i+1]
+					i++ // Skip the status argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					dateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := tasks.Execute(folderPath, verbose, status, dateProperties); err != nil {
+			log.Fatal(err)
+		}
+	case "stats":
+		folderPath := getDefaultFolderPath()
+		verbose := false
+		dateProperties := getDateProperties()
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				verbose = true
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					dateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := stats.Execute(folderPath, verbose, dateProperties); err != nil {
+			log.Fatal(err)
+		}
+	case "heatmap":
+		folderPath := getDefaultFolderPath()
+		opts := heatmap.Options{DateProperties: getDateProperties()}
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "--metric" {
+				if i+1 < len(os.Args) {
+					opts.Metric = os.Args[i+1]
+					i++ // Skip the metric argument
+				}
+			} else if arg == "--year" {
+				if i+1 < len(os.Args) {
+					years, err := parseYears(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Years = append(opts.Years, years...)
+					i++ // Skip the year argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
 			} else {
 				folderPath = arg
 			}
 		}
 
-		if err := serve.Execute(folderPath, verbose, port); err != nil {
+		if err := heatmap.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "review":
+		folderPath := getDefaultFolderPath()
+		opts := review.Options{
+			Year:           time.Now().Year() - 1,
+			DateProperties: getDateProperties(),
+		}
+		opts.PeopleFolder = getEnvOr("SALTHAVEN_PEOPLE_FOLDER", "")
+		opts.PlacesFolder = getEnvOr("SALTHAVEN_PLACES_FOLDER", "")
+		var positional []string
+
+		// Parse arguments: the last positional argument is the year, an earlier one the folder
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-o" || arg == "--output" {
+				if i+1 < len(os.Args) {
+					opts.Output = os.Args[i+1]
+					i++ // Skip the output argument
+				}
+			} else if arg == "--force" {
+				opts.Force = true
+			} else if arg == "--people" {
+				if i+1 < len(os.Args) {
+					opts.PeopleFolder = os.Args[i+1]
+					i++ // Skip the people folder argument
+				}
+			} else if arg == "--places" {
+				if i+1 < len(os.Args) {
+					opts.PlacesFolder = os.Args[i+1]
+					i++ // Skip the places folder argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				positional = append(positional, arg)
+			}
+		}
+		if len(positional) > 0 {
+			if year, err := strconv.Atoi(positional[len(positional)-1]); err == nil {
+				opts.Year = year
+				positional = positional[:len(positional)-1]
+			}
+		}
+		if len(positional) > 0 {
+			folderPath = positional[0]
+		}
+
+		if err := review.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "random":
+		folderPath := getDefaultFolderPath()
+		opts := random.Options{DateProperties: getDateProperties()}
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "--older" {
+				opts.WeightOlder = true
+			} else if arg == "--year" {
+				if i+1 < len(os.Args) {
+					years, err := parseYears(os.Args[i+1])
+					if err != nil {
+						log.Fatal(err)
+					}
+					opts.Years = append(opts.Years, years...)
+					i++ // Skip the year argument
+				}
+			} else if arg == "--tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Include = append(opts.Tags.Include, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
+			} else if arg == "--exclude-tag" {
+				if i+1 < len(os.Args) {
+					opts.Tags.Exclude = append(opts.Tags.Exclude, splitList(os.Args[i+1])...)
+					i++ // Skip the tag argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := random.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "graph":
+		folderPath := getDefaultFolderPath()
+		opts := graph.Options{
+			Format:         graph.DefaultFormat,
+			DateProperties: getDateProperties(),
+		}
+
+		// Parse arguments
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-f" || arg == "--format" {
+				if i+1 < len(os.Args) {
+					opts.Format = os.Args[i+1]
+					i++ // Skip the format argument
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				folderPath = arg
+			}
+		}
+
+		if err := graph.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "search":
+		folderPath := getDefaultFolderPath()
+		opts := search.Options{
+			Limit:          search.DefaultLimit,
+			DateProperties: getDateProperties(),
+		}
+		var positional []string
+
+		// Parse arguments: the last positional argument is the query, an earlier one the folder
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-n" || arg == "--limit" {
+				if i+1 < len(os.Args) {
+					if n, err := strconv.Atoi(os.Args[i+1]); err == nil {
+						opts.Limit = n
+						i++ // Skip the limit argument
+					}
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				positional = append(positional, arg)
+			}
+		}
+		if len(positional) == 0 {
+			log.Fatal("usage: salthaven search [folder_path] \"query\"")
+		}
+		if len(positional) > 1 {
+			folderPath = positional[0]
+		}
+		opts.Query = positional[len(positional)-1]
+
+		if err := search.Execute(folderPath, opts); err != nil {
+			log.Fatal(err)
+		}
+	case "related":
+		folderPath := getDefaultFolderPath()
+		opts := related.Options{
+			Limit:          related.DefaultLimit,
+			DateProperties: getDateProperties(),
+		}
+		var positional []string
+
+		// Parse arguments: the last positional argument is the note, an earlier one the folder
+		for i := 2; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "-v" || arg == "--verbose" {
+				opts.Verbose = true
+			} else if arg == "-n" || arg == "--limit" {
+				if i+1 < len(os.Args) {
+					if n, err := strconv.Atoi(os.Args[i+1]); err == nil {
+						opts.Limit = n
+						i++ // Skip the limit argument
+					}
+				}
+			} else if arg == "--date-properties" {
+				if i+1 < len(os.Args) {
+					opts.DateProperties = splitList(os.Args[i+1])
+					i++ // Skip the properties argument
+				}
+			} else {
+				positional = append(positional, arg)
+			}
+		}
+		if len(positional) == 0 {
+			log.Fatal("usage: salthaven related [folder_path] note.md")
+		}
+		if len(positional) > 1 {
+			folderPath = positional[0]
+		}
+		opts.Note = positional[len(positional)-1]
+
+		if err := related.Execute(folderPath, opts); err != nil {
 			log.Fatal(err)
 		}
 	default: